@@ -10,28 +10,26 @@ import (
 	"time"
 
 	"connector-recruitment/go-server/connectors/config"
-	"connector-recruitment/go-server/connectors/db"
 	"connector-recruitment/go-server/connectors/handler"
 	"connector-recruitment/go-server/connectors/interceptors"
 	"connector-recruitment/go-server/connectors/logger"
 	"connector-recruitment/go-server/connectors/service"
 	"connector-recruitment/go-server/connectors/storage"
 
-	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 type gRPCServer struct {
-	db            *db.Service
-	addr          string
-	secretManager *secretsmanager.SecretsManager
-	logger        logger.Logger
+	addr    string
+	storage storage.Storage
+	logger  logger.Logger
 }
 
-func NewGRPCServer(addr string, secretManager *secretsmanager.SecretsManager, logger logger.Logger, db *db.Service) *gRPCServer {
-	return &gRPCServer{addr: ":" + addr, secretManager: secretManager, logger: logger, db: db}
+func NewGRPCServer(addr string, storage storage.Storage, logger logger.Logger) *gRPCServer {
+	return &gRPCServer{addr: ":" + addr, storage: storage, logger: logger}
 }
 
 func (s *gRPCServer) Run(env config.Env) error {
@@ -41,18 +39,26 @@ func (s *gRPCServer) Run(env config.Env) error {
 	}
 
 	// Create a new gRPC server instance
-	grpcServer := grpc.NewServer(
-		grpc.UnaryInterceptor(interceptors.LoggingUnaryInterceptor(s.logger)),
-	)
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			interceptors.LoggingUnaryInterceptor(s.logger),
+			interceptors.ActorUnaryInterceptor(),
+		),
+	}
+	if tracing(env) {
+		// a span per call, parent of the spans of its queries
+		opts = append(opts, grpc.StatsHandler(otelgrpc.NewServerHandler()))
+	}
+	grpcServer := grpc.NewServer(opts...)
 
 	// health server
 	healthServer := health.NewServer()
 	healthpb.RegisterHealthServer(grpcServer, healthServer)
 	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
 
-	// Setup storage and register gRPC services
-	storage := storage.NewSqlStorage(s.db.DBPool, s.secretManager, s.logger)
-	connectorService := service.NewConnectorService(storage, s.secretManager, s.logger)
+	// Register gRPC services
+	connectorService := service.NewConnectorService(s.storage, s.logger)
+	defer connectorService.Close()
 	handler.NewGrpcConnectorsService(grpcServer, connectorService, s.logger)
 
 	s.logger.Info("Starting gRPC server", "addr", lis.Addr().String())
@@ -61,6 +67,17 @@ func (s *gRPCServer) Run(env config.Env) error {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	// only the sql storage keeps secrets and connectors apart
+	if sqlStorage, ok := s.storage.(*storage.SqlStorage); ok && env.ReconcileInterval > 0 {
+		go runReconciler(ctx, sqlStorage, time.Duration(env.ReconcileInterval)*time.Second,
+			time.Duration(env.ReconcileMinAge)*time.Second, env.ReconcileRepair, s.logger)
+	}
+	if env.HealthProbeInterval > 0 {
+		prober := service.NewHealthProber(connectorService, time.Duration(env.HealthProbeInterval)*time.Second,
+			env.HealthProbeConcurrency, s.logger)
+		go prober.Run(ctx)
+	}
+
 	serverErrCh := make(chan error, 1)
 	go func() {
 		err := grpcServer.Serve(lis)
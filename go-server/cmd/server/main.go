@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
+	"os"
+	"time"
 
 	"connector-recruitment/go-server/connectors/config"
 	"connector-recruitment/go-server/connectors/db"
 	"connector-recruitment/go-server/connectors/logger"
+	"connector-recruitment/go-server/connectors/storage"
 )
 
 func main() {
@@ -18,14 +23,94 @@ func main() {
 	slogger := logger.NewProductionLogger(env)
 	slog.SetDefault(slogger)
 
-	// get DB
-	db := db.NewDB(env)
-	// setup AWS session for LocalStack
-	smClient := config.NewSecretClient(env)
+	// setup the exporter of the traces, before anything gets a tracer
+	shutdownTracing, err := setupTracing(context.Background(), env)
+	if err != nil {
+		panic(err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slogger.Error("failed to flush traces", "err", err)
+		}
+	}()
+
+	// get DB, unless everything is kept in memory
+	var database *db.Service
+	switch env.Storage {
+	case config.StoragePostgres:
+		database = db.NewDB(env, slogger)
+	case config.StorageMemory:
+		slogger.Warn("using the memory storage, nothing survives a restart")
+	default:
+		panic(fmt.Sprintf("unknown STORAGE %q, expected %q or %q", env.Storage, config.StoragePostgres, config.StorageMemory))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if database == nil {
+			fmt.Fprintf(os.Stderr, "migrate requires STORAGE=%s\n", config.StoragePostgres)
+			os.Exit(2)
+		}
+		os.Exit(migrateCommand(os.Args[2:], database))
+	}
+	if database != nil && env.PostgresAutoMigrate {
+		if err := database.Migrate(); err != nil {
+			panic(err)
+		}
+		slogger.Info("database migrated")
+	}
 
-	slogger.Info("successfully connected to postgres and has run migrations")
-	grpcServer := NewGRPCServer(env.RPCPort, smClient, slogger, db)
+	// setup the secret store holding the connectors' tokens
+	secrets, err := newSecretStore(env, database)
+	if err != nil {
+		panic(err)
+	}
+	var tokenCache *storage.CachedSecretStore
+	if env.TokenCacheSize > 0 {
+		tokenCache = storage.NewCachedSecretStore(secrets, env.TokenCacheSize, time.Duration(env.TokenCacheTTL)*time.Second)
+		secrets = tokenCache
+	}
+	namer, err := storage.NewSecretNamer(env.SecretNameTemplate, string(env.AppEnv))
+	if err != nil {
+		panic(err)
+	}
+
+	var store storage.Storage
+	var sqlStorage *storage.SqlStorage
+	if database != nil {
+		sqlStorage = storage.NewSqlStorage(database.DBPool, database.Replica, secrets, namer, slogger)
+		store = sqlStorage
+	} else {
+		store = storage.NewMemoryStorage(secrets, namer, slogger)
+	}
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "reconcile", "rename-secrets":
+			if sqlStorage == nil {
+				fmt.Fprintf(os.Stderr, "%s requires STORAGE=%s\n", os.Args[1], config.StoragePostgres)
+				os.Exit(2)
+			}
+			if os.Args[1] == "reconcile" {
+				os.Exit(reconcileCommand(os.Args[2:], sqlStorage))
+			}
+			os.Exit(renameSecretsCommand(os.Args[2:], sqlStorage))
+		default:
+			fmt.Fprintf(os.Stderr, "unknown command %q, expected: migrate, reconcile, rename-secrets\n", os.Args[1])
+			os.Exit(2)
+		}
+	}
+
+	if database != nil {
+		slogger.Info("successfully connected to postgres")
+	}
+	grpcServer := NewGRPCServer(env.RPCPort, store, slogger)
 	if err := grpcServer.Run(env); err != nil {
 		slogger.Error("failed to serve: ", "err", err)
 	}
+
+	if tokenCache != nil {
+		stats := tokenCache.Stats()
+		slogger.Info("token cache stats", "hits", stats.Hits, "misses", stats.Misses, "evictions", stats.Evictions)
+	}
 }
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"connector-recruitment/go-server/connectors/db"
+
+	"github.com/golang-migrate/migrate/v4"
+)
+
+const migrateUsage = `usage: server migrate <command>
+  up [N]       apply all pending migrations, or the next N
+  down N       revert the last N migrations
+  down -all    revert every migration
+  status       print the current version and the pending migrations
+  force V      set the version to V and clear the dirty flag, without running anything`
+
+// migrateCommand runs `server migrate up|down|status|force` against the embedded migrations.
+func migrateCommand(args []string, database *db.Service) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	if args[0] == "status" {
+		return migrateStatus(database)
+	}
+
+	m, err := database.NewMigrator()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer m.Close()
+
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		err = m.Up()
+	case args[0] == "up" && len(args) == 2:
+		n, convErr := strconv.Atoi(args[1])
+		if convErr != nil || n <= 0 {
+			fmt.Fprintln(os.Stderr, "up expects a positive number of migrations")
+			return 2
+		}
+		err = m.Steps(n)
+	case args[0] == "down" && len(args) == 2 && args[1] == "-all":
+		err = m.Down()
+	case args[0] == "down" && len(args) == 2:
+		n, convErr := strconv.Atoi(args[1])
+		if convErr != nil || n <= 0 {
+			fmt.Fprintln(os.Stderr, "down expects a positive number of migrations, or -all")
+			return 2
+		}
+		err = m.Steps(-n)
+	case args[0] == "force" && len(args) == 2:
+		v, convErr := strconv.Atoi(args[1])
+		if convErr != nil || v < -1 {
+			fmt.Fprintln(os.Stderr, "force expects a version, or -1 for none")
+			return 2
+		}
+		err = m.Force(v)
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	if errors.Is(err, migrate.ErrNoChange) {
+		fmt.Println("no change")
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "migrate failed:", err)
+		return 1
+	}
+	return printVersion(m)
+}
+
+func migrateStatus(database *db.Service) int {
+	m, err := database.NewMigrator()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer m.Close()
+
+	if code := printVersion(m); code != 0 {
+		return code
+	}
+	current, _, err := m.Version()
+	none := errors.Is(err, migrate.ErrNilVersion)
+	if err != nil && !none {
+		fmt.Fprintln(os.Stderr, "failed to read version:", err)
+		return 1
+	}
+	versions, err := db.MigrationVersions()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	pending := 0
+	for _, v := range versions {
+		if none || v > current {
+			fmt.Println("pending:", v)
+			pending++
+		}
+	}
+	fmt.Printf("%d of %d migrations pending\n", pending, len(versions))
+	return 0
+}
+
+func printVersion(m *migrate.Migrate) int {
+	version, dirty, err := m.Version()
+	switch {
+	case errors.Is(err, migrate.ErrNilVersion):
+		fmt.Println("version: none")
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "failed to read version:", err)
+		return 1
+	case dirty:
+		fmt.Printf("version: %d (dirty: a migration failed, fix the schema and force the version it is at)\n", version)
+	default:
+		fmt.Printf("version: %d\n", version)
+	}
+	return 0
+}
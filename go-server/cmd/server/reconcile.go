@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"connector-recruitment/go-server/connectors/logger"
+	"connector-recruitment/go-server/connectors/storage"
+)
+
+// reconcileCommand runs `server reconcile [-repair] [-min-age 15m]`: it prints the orphan
+// secrets and connector rows, and with -repair deletes the secrets and suspends the
+// connectors. It exits with 1 when orphans remain.
+func reconcileCommand(args []string, sqlStorage *storage.SqlStorage) int {
+	fs := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	repair := fs.Bool("repair", false, "delete the orphan secrets and suspend the orphan connectors")
+	minAge := fs.Duration("min-age", storage.DefaultReconcileMinAge, "skip the orphans younger than this")
+	timeout := fs.Duration("timeout", 5*time.Minute, "give up after this duration")
+	fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	report, err := sqlStorage.Reconcile(ctx, *repair, *minAge)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "reconcile failed:", err)
+		return 1
+	}
+
+	for _, name := range report.OrphanSecrets {
+		fmt.Println("orphan secret:", name)
+	}
+	for _, id := range report.OrphanConnectors {
+		fmt.Println("orphan connector:", id)
+	}
+	for _, name := range report.Repaired {
+		fmt.Println("repaired:", name)
+	}
+	for _, e := range report.Errors {
+		fmt.Fprintln(os.Stderr, "repair failed:", e)
+	}
+
+	orphans := len(report.OrphanSecrets) + len(report.OrphanConnectors)
+	fmt.Printf("%d orphans found, %d repaired\n", orphans, len(report.Repaired))
+	if orphans > len(report.Repaired) {
+		return 1
+	}
+	return 0
+}
+
+// runReconciler reconciles secrets and connectors every interval until ctx is done.
+func runReconciler(ctx context.Context, sqlStorage *storage.SqlStorage, interval, minAge time.Duration, repair bool, logger logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		report, err := sqlStorage.Reconcile(ctx, repair, minAge)
+		if err != nil {
+			logger.Error("reconcile failed", "err", err)
+			continue
+		}
+		if len(report.OrphanSecrets) == 0 && len(report.OrphanConnectors) == 0 {
+			continue
+		}
+		logger.Warn("reconcile found orphans",
+			"orphan-secrets", report.OrphanSecrets,
+			"orphan-connectors", report.OrphanConnectors,
+			"repaired", len(report.Repaired),
+			"errors", report.Errors)
+	}
+}
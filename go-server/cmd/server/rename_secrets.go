@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"connector-recruitment/go-server/connectors/storage"
+)
+
+// renameSecretsCommand runs `server rename-secrets [-dry-run]`: it moves the connector
+// secrets to the names of SECRET_NAME_TEMPLATE. It exits with 1 when a rename failed.
+func renameSecretsCommand(args []string, sqlStorage *storage.SqlStorage) int {
+	fs := flag.NewFlagSet("rename-secrets", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "print the renames without doing them")
+	timeout := fs.Duration("timeout", 30*time.Minute, "give up after this duration")
+	fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	renames, err := sqlStorage.RenameConnectorSecrets(ctx, *dryRun)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "rename failed:", err)
+		return 1
+	}
+
+	failed := 0
+	for _, r := range renames {
+		if r.Err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "connector %s: %s -> %s failed: %v\n", r.ConnectorID, r.From, r.To, r.Err)
+			continue
+		}
+		fmt.Printf("connector %s: %s -> %s\n", r.ConnectorID, r.From, r.To)
+	}
+
+	if *dryRun {
+		fmt.Printf("%d secrets to rename\n", len(renames))
+		return 0
+	}
+	fmt.Printf("%d secrets renamed, %d failed\n", len(renames)-failed, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"connector-recruitment/go-server/connectors/config"
+	"connector-recruitment/go-server/connectors/db"
+	"connector-recruitment/go-server/connectors/storage"
+)
+
+// newSecretStore creates the secret store selected by env.SecretStore. db is nil with the
+// memory storage.
+func newSecretStore(env config.Env, db *db.Service) (storage.SecretStore, error) {
+	switch env.SecretStore {
+	case config.SecretStoreAWS:
+		if env.AWSRegion == "" {
+			return nil, errors.New("AWS_REGION is required by the aws secret store")
+		}
+		return storage.NewAWSSecretStore(config.NewSecretClient(env), env.AWSKmsKeyID), nil
+	case config.SecretStorePostgres:
+		if db == nil {
+			return nil, fmt.Errorf("the postgres secret store requires STORAGE=%s", config.StoragePostgres)
+		}
+		if env.SecretStoreKEK == "" {
+			return nil, errors.New("SECRET_STORE_KEK is required by the postgres secret store")
+		}
+		kek, err := base64.StdEncoding.DecodeString(env.SecretStoreKEK)
+		if err != nil {
+			return nil, fmt.Errorf("SECRET_STORE_KEK is not valid base64: %w", err)
+		}
+		return storage.NewPostgresSecretStore(db.DBPool, kek)
+	case config.SecretStoreMemory:
+		return storage.NewMemorySecretStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown SECRET_STORE %q, expected %q, %q or %q",
+			env.SecretStore, config.SecretStoreAWS, config.SecretStorePostgres, config.SecretStoreMemory)
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"connector-recruitment/go-server/connectors/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// setupTracing registers, with POSTGRES_TRACING, the TracerProvider the query and gRPC
+// spans go to, exporting them in batches to OTEL_EXPORTER_OTLP_ENDPOINT. The returned
+// shutdown flushes the spans not exported yet.
+func setupTracing(ctx context.Context, env config.Env) (shutdown func(context.Context) error, err error) {
+	if !tracing(env) {
+		return func(context.Context) error { return nil }, nil
+	}
+	exporter, err := otlptracegrpc.New(ctx, otlptracegrpc.WithEndpointURL(env.OtelExporterOtlpEndpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the trace exporter: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return provider.Shutdown, nil
+}
+
+// tracing tells whether the queries, and the calls running them, are traced.
+func tracing(env config.Env) bool {
+	return env.Storage == config.StoragePostgres && env.PostgresTracing
+}
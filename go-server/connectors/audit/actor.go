@@ -0,0 +1,38 @@
+// Package audit carries who is behind a request down to the storage, which records it
+// with every connector mutation.
+package audit
+
+import "context"
+
+// ActorHeader is the gRPC metadata key naming the user or service behind a request.
+const ActorHeader = "x-actor"
+
+const (
+	// AnonymousActor is recorded for requests without ActorHeader.
+	AnonymousActor = "anonymous"
+	// SystemActor is recorded for changes made by the server itself, outside of a
+	// request: health probes, reconciliation, migration commands.
+	SystemActor = "system"
+)
+
+// Actor is who made a change and through which RPC.
+type Actor struct {
+	ID          string
+	PeerAddress string
+	Method      string
+}
+
+type actorKey struct{}
+
+// WithActor returns a context carrying the actor.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// FromContext returns the actor of the request, or SystemActor outside of a request.
+func FromContext(ctx context.Context) Actor {
+	if actor, ok := ctx.Value(actorKey{}).(Actor); ok {
+		return actor
+	}
+	return Actor{ID: SystemActor}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
 
 	"github.com/joho/godotenv"
@@ -66,29 +67,114 @@ const (
 	Production = "production"
 )
 
+// Storage backends
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+)
+
+// Secret store backends
+const (
+	SecretStoreAWS      = "aws"
+	SecretStorePostgres = "postgres"
+	SecretStoreMemory   = "memory"
+)
+
 type Env struct {
 	AppEnv   ApplicationEnvironment `default:"dev" split_words:"true"`
 	Name     string                 `envconfig:"SERVICE_NAME" required:"true"`
 	LogLevel string                 `envconfig:"LOG_LEVEL" required:"true" split_words:"true"`
-
-	PostgresHost       string `required:"true" split_words:"true"`
-	PostgresPort       string `required:"true" split_words:"true"`
-	PostgresPoolSize   int    `required:"true" split_words:"true"`
-	PostgresSecureMode bool   `required:"true" split_words:"true"`
-	PostgresUser       string `required:"true" split_words:"true"`
-	PostgresPassword   string `required:"true" split_words:"true"`
-	PostgresDatabase   string `required:"true" split_words:"true"`
-	PostgresDebug      bool   `default:"false" split_words:"true"`
-
-	AWSRegion            string `envconfig:"AWS_REGION" required:"true" split_words:"true"`
-	AWSEndpoint          string `envconfig:"AWS_ENDPOINT" required:"true" split_words:"true"`
-	AWSForcePathStyle    bool   `envconfig:"AWS_FORCE_PATH_STYLE" required:"true" split_words:"true"`
-	AWSCredentialsID     string `envconfig:"AWS_CREDENTIALS_ID" required:"true" split_words:"true"`
-	AWSCredentialsSecret string `envconfig:"AWS_CREDENTIALS_SECRET" required:"true" split_words:"true"`
+	// LogRedactKeys are attribute keys whose values are never logged, on top of token,
+	// password, secret, authorization, api_key and kek.
+	LogRedactKeys []string `split_words:"true"`
+
+	// Storage selects where connectors, tenants, routing rules, messages and audit events are
+	// kept: StoragePostgres or StorageMemory, which needs no database but is lost on restart.
+	Storage string `default:"postgres"`
+
+	// The Postgres settings are only read, and host, port, pool size, user and database only
+	// required, with Storage set to StoragePostgres.
+	PostgresHost       string `split_words:"true"`
+	PostgresPort       string `split_words:"true"`
+	PostgresPoolSize   int    `split_words:"true"`
+	PostgresSecureMode bool   `default:"false" split_words:"true"`
+	PostgresUser       string `split_words:"true"`
+	PostgresPassword   string `split_words:"true"`
+	PostgresDatabase   string `split_words:"true"`
+	// PostgresDebug logs every query, with its duration and rows, at debug level. Queries
+	// slower than PostgresSlowQueryThreshold milliseconds are always logged as warnings; 0
+	// disables that. PostgresTracing attaches a span to every query, and to every gRPC
+	// call they run in, exported over OTLP/gRPC to OtelExporterOtlpEndpoint, which it
+	// requires. The other OTEL_EXPORTER_OTLP_* variables configure the exporter too.
+	PostgresDebug              bool   `default:"false" split_words:"true"`
+	PostgresSlowQueryThreshold int    `default:"500" split_words:"true"`
+	PostgresTracing            bool   `default:"false" split_words:"true"`
+	OtelExporterOtlpEndpoint   string `envconfig:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	// PostgresSSLMode is the libpq sslmode of the connections. It defaults to verify-full
+	// with PostgresSecureMode, and to disable otherwise. PostgresSSLRootCert is the CA
+	// bundle verifying the server (the system roots when empty), PostgresSSLCert and
+	// PostgresSSLKey the client certificate and key; all three are file paths.
+	PostgresSSLMode     string `envconfig:"POSTGRES_SSL_MODE"`
+	PostgresSSLRootCert string `envconfig:"POSTGRES_SSL_ROOT_CERT"`
+	PostgresSSLCert     string `envconfig:"POSTGRES_SSL_CERT"`
+	PostgresSSLKey      string `envconfig:"POSTGRES_SSL_KEY"`
+	// PostgresPoolMinConns connections are kept open, out of at most PostgresPoolSize.
+	// Connections are closed after PostgresPoolMaxConnLifetime seconds, or after being idle
+	// for PostgresPoolMaxConnIdleTime seconds; idle connections are checked every
+	// PostgresPoolHealthCheckPeriod seconds.
+	PostgresPoolMinConns          int `default:"0" split_words:"true"`
+	PostgresPoolMaxConnLifetime   int `default:"3600" split_words:"true"`
+	PostgresPoolMaxConnIdleTime   int `default:"1800" split_words:"true"`
+	PostgresPoolHealthCheckPeriod int `default:"60" split_words:"true"`
+	// PostgresReplicaHost is a read replica serving GetConnector and GetConnectors; empty
+	// sends every query to the primary. The replica port defaults to PostgresPort, the
+	// credentials and database are the primary's.
+	PostgresReplicaHost string `split_words:"true"`
+	PostgresReplicaPort string `split_words:"true"`
+	// PostgresReplicaMaxLag is the replication lag, in seconds, beyond which reads go back to
+	// the primary. The replica is checked every PostgresReplicaCheckInterval seconds.
+	PostgresReplicaMaxLag        int `default:"5" split_words:"true"`
+	PostgresReplicaCheckInterval int `default:"5" split_words:"true"`
+	// PostgresAutoMigrate applies the pending migrations on startup. Otherwise run `server migrate up`.
+	PostgresAutoMigrate bool `default:"false" split_words:"true"`
+
+	// SecretStore selects where connector secrets are kept: SecretStoreAWS, SecretStorePostgres
+	// or SecretStoreMemory.
+	SecretStore string `default:"aws" split_words:"true"`
+	// SecretStoreKEK is the base64 encoded 32-byte key-encryption key of the postgres secret store.
+	SecretStoreKEK string `envconfig:"SECRET_STORE_KEK" split_words:"true"`
+	// TokenCacheSize is the number of connector tokens kept in memory for TokenCacheTTL
+	// seconds; 0 disables the cache.
+	TokenCacheSize int `default:"1000" split_words:"true"`
+	TokenCacheTTL  int `envconfig:"TOKEN_CACHE_TTL" default:"300" split_words:"true"`
+	// SecretNameTemplate names the secrets of new connectors; it may use {tenant}, {id} and {env}.
+	SecretNameTemplate string `default:"connectors/{tenant}/{id}" split_words:"true"`
+
+	// The AWS settings are only required by the aws secret store.
+	AWSRegion            string `envconfig:"AWS_REGION" required:"false" split_words:"true"`
+	AWSEndpoint          string `envconfig:"AWS_ENDPOINT" required:"false" split_words:"true"`
+	AWSForcePathStyle    bool   `envconfig:"AWS_FORCE_PATH_STYLE" required:"false" split_words:"true"`
+	AWSCredentialsID     string `envconfig:"AWS_CREDENTIALS_ID" required:"false" split_words:"true"`
+	AWSCredentialsSecret string `envconfig:"AWS_CREDENTIALS_SECRET" required:"false" split_words:"true"`
 	AWSCredentialsToken  string `envconfig:"AWS_CREDENTIALS_TOKEN" required:"false" split_words:"true"`
+	// AWSKmsKeyID is the KMS key encrypting new secrets; empty uses the account's default key.
+	AWSKmsKeyID string `envconfig:"AWS_KMS_KEY_ID" required:"false"`
 
 	RPCGracefulShutdownTimeout int    `envconfig:"RPC_GRACEFUL_SHUTDOWN_TIMEOUT" required:"true" split_words:"true"`
 	RPCPort                    string `envconfig:"RPC_PORT" required:"true" split_words:"true"`
+
+	// HealthProbeInterval is the number of seconds between two health probes of the
+	// connectors; 0 disables probing.
+	HealthProbeInterval    int `default:"300" split_words:"true"`
+	HealthProbeConcurrency int `default:"4" split_words:"true"`
+
+	// ReconcileInterval is the number of seconds between two reconciliations of the
+	// secrets with the connectors; 0 disables them. ReconcileRepair deletes the orphan
+	// secrets and suspends the orphan connectors. Orphans younger than ReconcileMinAge
+	// seconds are left alone: they may belong to a connector being created.
+	ReconcileInterval int  `default:"3600" split_words:"true"`
+	ReconcileRepair   bool `default:"false" split_words:"true"`
+	ReconcileMinAge   int  `default:"900" split_words:"true"`
 }
 
 func LoadEnv(env *Env) error {
@@ -128,5 +214,17 @@ func LoadEnv(env *Env) error {
 		return err
 	}
 
+	if env.Storage == StoragePostgres {
+		if env.PostgresSSLMode == "" {
+			env.PostgresSSLMode = "disable"
+			if env.PostgresSecureMode {
+				env.PostgresSSLMode = "verify-full"
+			}
+		}
+		if err := env.validatePostgres(); err != nil {
+			return fmt.Errorf("invalid postgres config: %w", err)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// sslModes are the libpq sslmode values, from the least to the most secure.
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// validatePostgres reports every invalid Postgres setting, naming its variable.
+func (env *Env) validatePostgres() error {
+	var problems []error
+	invalid := func(format string, args ...any) {
+		problems = append(problems, fmt.Errorf(format, args...))
+	}
+
+	for _, required := range []struct{ name, value string }{
+		{"POSTGRES_HOST", env.PostgresHost},
+		{"POSTGRES_USER", env.PostgresUser},
+		{"POSTGRES_DATABASE", env.PostgresDatabase},
+	} {
+		if required.value == "" {
+			invalid("%s is required with STORAGE=%s", required.name, StoragePostgres)
+		}
+	}
+	if !validPort(env.PostgresPort) {
+		invalid("POSTGRES_PORT must be a port number, got %q", env.PostgresPort)
+	}
+	if env.PostgresReplicaPort != "" && !validPort(env.PostgresReplicaPort) {
+		invalid("POSTGRES_REPLICA_PORT must be a port number, got %q", env.PostgresReplicaPort)
+	}
+
+	if !validSSLMode(env.PostgresSSLMode) {
+		invalid("POSTGRES_SSL_MODE must be one of %v, got %q", sslModes, env.PostgresSSLMode)
+	} else if env.PostgresSecureMode && env.PostgresSSLMode != "verify-ca" && env.PostgresSSLMode != "verify-full" {
+		invalid("POSTGRES_SECURE_MODE requires POSTGRES_SSL_MODE verify-ca or verify-full, got %q", env.PostgresSSLMode)
+	}
+	if (env.PostgresSSLCert == "") != (env.PostgresSSLKey == "") {
+		invalid("POSTGRES_SSL_CERT and POSTGRES_SSL_KEY must be set together")
+	}
+	for _, file := range []struct{ name, path string }{
+		{"POSTGRES_SSL_ROOT_CERT", env.PostgresSSLRootCert},
+		{"POSTGRES_SSL_CERT", env.PostgresSSLCert},
+		{"POSTGRES_SSL_KEY", env.PostgresSSLKey},
+	} {
+		if file.path == "" {
+			continue
+		}
+		if env.PostgresSSLMode == "disable" {
+			invalid("%s is set but POSTGRES_SSL_MODE is disable", file.name)
+		}
+		if _, err := os.Stat(file.path); err != nil {
+			invalid("%s: %w", file.name, err)
+		}
+	}
+
+	if env.PostgresPoolSize < 1 {
+		invalid("POSTGRES_POOL_SIZE must be at least 1, got %d", env.PostgresPoolSize)
+	}
+	if env.PostgresPoolMinConns < 0 || env.PostgresPoolMinConns > env.PostgresPoolSize {
+		invalid("POSTGRES_POOL_MIN_CONNS must be between 0 and POSTGRES_POOL_SIZE (%d), got %d",
+			env.PostgresPoolSize, env.PostgresPoolMinConns)
+	}
+	for _, seconds := range []struct {
+		name  string
+		value int
+	}{
+		{"POSTGRES_POOL_MAX_CONN_LIFETIME", env.PostgresPoolMaxConnLifetime},
+		{"POSTGRES_POOL_MAX_CONN_IDLE_TIME", env.PostgresPoolMaxConnIdleTime},
+		{"POSTGRES_POOL_HEALTH_CHECK_PERIOD", env.PostgresPoolHealthCheckPeriod},
+	} {
+		if seconds.value <= 0 {
+			invalid("%s must be a positive number of seconds, got %d", seconds.name, seconds.value)
+		}
+	}
+	if env.PostgresSlowQueryThreshold < 0 {
+		invalid("POSTGRES_SLOW_QUERY_THRESHOLD must not be negative, got %d", env.PostgresSlowQueryThreshold)
+	}
+	if env.PostgresTracing && env.OtelExporterOtlpEndpoint == "" {
+		invalid("POSTGRES_TRACING requires OTEL_EXPORTER_OTLP_ENDPOINT, the collector the spans are exported to")
+	}
+
+	if env.PostgresReplicaHost != "" {
+		if env.PostgresReplicaCheckInterval <= 0 {
+			invalid("POSTGRES_REPLICA_CHECK_INTERVAL must be a positive number of seconds, got %d", env.PostgresReplicaCheckInterval)
+		}
+		if env.PostgresReplicaMaxLag < 0 {
+			invalid("POSTGRES_REPLICA_MAX_LAG must not be negative, got %d", env.PostgresReplicaMaxLag)
+		}
+	}
+	return errors.Join(problems...)
+}
+
+func validSSLMode(mode string) bool {
+	for _, m := range sslModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n <= 65535
+}
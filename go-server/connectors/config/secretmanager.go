@@ -10,11 +10,17 @@ import (
 func NewSecretClient(env Env) *secretsmanager.SecretsManager {
 	// Setup AWS session for LocalStack
 	awsConfig := &aws.Config{
-		Credentials:      credentials.NewStaticCredentials(env.AWSCredentialsID, env.AWSCredentialsSecret, env.AWSCredentialsToken),
 		Region:           aws.String(env.AWSRegion),
-		Endpoint:         aws.String(env.AWSEndpoint), // Points to the LocalStack container
 		S3ForcePathStyle: aws.Bool(env.AWSForcePathStyle),
 	}
+	// Without static credentials the SDK's default chain (env, shared config, instance role) is used.
+	if env.AWSCredentialsID != "" {
+		awsConfig.Credentials = credentials.NewStaticCredentials(env.AWSCredentialsID, env.AWSCredentialsSecret, env.AWSCredentialsToken)
+	}
+	// The endpoint points to the LocalStack container; it is left unset to reach AWS itself.
+	if env.AWSEndpoint != "" {
+		awsConfig.Endpoint = aws.String(env.AWSEndpoint)
+	}
 	sess := session.Must(session.NewSession(awsConfig))
 	smClient := secretsmanager.New(sess)
 
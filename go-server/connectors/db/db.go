@@ -2,65 +2,27 @@ package db
 
 import (
 	"context"
-	"crypto/tls"
 	"database/sql"
 	"fmt"
 	"log"
-	"path/filepath"
-	"runtime"
+	"net"
+	"net/url"
 	"strconv"
 	"time"
 
 	"connector-recruitment/go-server/connectors/config"
-	"connector-recruitment/go-server/connectors/internal"
-
-	"github.com/golang-migrate/migrate"
-	"github.com/golang-migrate/migrate/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"connector-recruitment/go-server/connectors/logger"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/stdlib"
 	_ "github.com/joho/godotenv/autoload"
 )
 
-// migrateDB syncs the `dbname` with the migrations specified in cwd()/sql
-func migrateDB(dbname string, db *sql.DB) error {
-	// log.Println("Migrating database...")
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
-	if err != nil {
-		log.Println(err)
-		return err
-	}
-
-	log.Println("driver gotten")
-
-	dir := internal.GetPackagePath()
-	dir = filepath.Join(dir, "sql/migrations")
-	migrationsDir := fmt.Sprintf("file:///%s", dir)
-
-	log.Println("Migrations dir", dir)
-
-	m, err := migrate.NewWithDatabaseInstance(
-		migrationsDir,
-		dbname, driver)
-	if err != nil {
-		log.Println(err)
-		return err
-	}
-
-	log.Println("NewWithDatabaseInstance")
-
-	if err := m.Up(); err != migrate.ErrNoChange {
-		log.Println(err)
-		return err
-	}
-
-	return nil
-}
-
 type Service struct {
 	DBPool *pgxpool.Pool // TODO: depreciate this and make use of the DB field only
 	DB     *sql.DB
+	// Replica serves the reads that allow it, nil without a configured replica.
+	Replica *Replica
 }
 
 var (
@@ -68,35 +30,44 @@ var (
 	dbInstance *Service
 )
 
-func NewDB(env config.Env) *Service {
+func NewDB(env config.Env, log logger.Logger) *Service {
 	// Reuse Connection
 	if dbInstance != nil {
 		return dbInstance
 	}
 	database = env.PostgresDatabase
-	connStr := dsnFromEnv(env)
 
 	// attempts to create db pool
-	sqldb, postgresdb, err := newStdDB(connStr, env)
+	tracer := newQueryTracer(env, log)
+	sqldb, postgresdb, err := newStdDB(env, tracer)
 	if err != nil {
 		panic(err)
 	}
 
-	maxOpenConns := 4 * runtime.GOMAXPROCS(0)
-	sqldb.SetMaxOpenConns(maxOpenConns)
-	sqldb.SetMaxIdleConns(maxOpenConns)
-
-	/*
-		if err := migrateDB(env.PostgresDatabase, sqldb); err != nil {
-			fmt.Printf("failed to migrate db: %v\n", err)
-			log.Fatalf("failed to migrate db: %v", err)
-		}
-	*/
+	// the std db borrows its connections from the pool
+	sqldb.SetMaxOpenConns(env.PostgresPoolSize)
+	sqldb.SetMaxIdleConns(env.PostgresPoolSize)
 
 	dbInstance = &Service{
 		DBPool: postgresdb,
 		DB:     sqldb,
 	}
+
+	if env.PostgresReplicaHost != "" {
+		replicaEnv := env
+		replicaEnv.PostgresHost = env.PostgresReplicaHost
+		if env.PostgresReplicaPort != "" {
+			replicaEnv.PostgresPort = env.PostgresReplicaPort
+		}
+		replicaPool, err := newPool(context.Background(), replicaEnv, tracer)
+		if err != nil {
+			panic(err)
+		}
+		dbInstance.Replica = newReplica(postgresdb, replicaPool,
+			time.Duration(env.PostgresReplicaMaxLag)*time.Second,
+			time.Duration(env.PostgresReplicaCheckInterval)*time.Second)
+		dbInstance.Replica.start()
+	}
 	return dbInstance
 }
 
@@ -144,37 +115,81 @@ func (s *Service) Health() map[string]string {
 		stats["message"] = "Many connections are being closed due to max lifetime, consider increasing max lifetime or revising the connection usage pattern."
 	}
 
+	if s.Replica != nil {
+		for k, v := range s.Replica.Health() {
+			stats["replica_"+k] = v
+		}
+	}
+
 	return stats
 }
 
 // Close closes the database connection.
 func (s *Service) Close() error {
 	log.Printf("Disconnected from database: %s", database)
+	if s.Replica != nil {
+		s.Replica.Close()
+	}
 	return s.DB.Close()
 }
 
+// dsnFromEnv is the connection string of the database, with the TLS settings of env.
 func dsnFromEnv(env config.Env) string {
-	_, err := strconv.Atoi(env.PostgresPort)
+	params := url.Values{}
+	params.Set("sslmode", env.PostgresSSLMode)
+	if env.PostgresSSLRootCert != "" {
+		params.Set("sslrootcert", env.PostgresSSLRootCert)
+	}
+	if env.PostgresSSLCert != "" {
+		params.Set("sslcert", env.PostgresSSLCert)
+		params.Set("sslkey", env.PostgresSSLKey)
+	}
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(env.PostgresUser, env.PostgresPassword),
+		Host:     net.JoinHostPort(env.PostgresHost, env.PostgresPort),
+		Path:     "/" + env.PostgresDatabase,
+		RawQuery: params.Encode(),
+	}
+	return dsn.String()
+}
+
+// poolConfig is the configuration of a pool of connections to the database of env. env is
+// expected to be validated by config.LoadEnv.
+func poolConfig(env config.Env) (*pgxpool.Config, error) {
+	cfg, err := pgxpool.ParseConfig(dsnFromEnv(env))
 	if err != nil {
-		panic(fmt.Sprintf("Invalid Postgres port: %s", env.PostgresPort))
+		return nil, fmt.Errorf("invalid postgres config: %w", err)
 	}
+	cfg.MaxConns = int32(env.PostgresPoolSize)
+	cfg.MinConns = int32(env.PostgresPoolMinConns)
+	cfg.MaxConnLifetime = time.Duration(env.PostgresPoolMaxConnLifetime) * time.Second
+	cfg.MaxConnIdleTime = time.Duration(env.PostgresPoolMaxConnIdleTime) * time.Second
+	cfg.HealthCheckPeriod = time.Duration(env.PostgresPoolHealthCheckPeriod) * time.Second
+	return cfg, nil
+}
 
-	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", env.PostgresUser, env.PostgresPassword, env.PostgresHost, env.PostgresPort, env.PostgresDatabase)
+// newPool opens a pool of connections to the database of env, running its queries through
+// tracer unless it is nil.
+func newPool(ctx context.Context, env config.Env, tracer pgx.QueryTracer) (*pgxpool.Pool, error) {
+	cfg, err := poolConfig(env)
+	if err != nil {
+		return nil, err
+	}
+	if tracer != nil {
+		cfg.ConnConfig.Tracer = tracer
+	}
+	return pgxpool.NewWithConfig(ctx, cfg)
 }
 
-func newStdDB(connStr string, env config.Env) (*sql.DB, *pgxpool.Pool, error) {
-	dbpool, err := pgxpool.New(context.Background(), connStr)
+func newStdDB(env config.Env, tracer pgx.QueryTracer) (*sql.DB, *pgxpool.Pool, error) {
+	dbpool, err := newPool(context.Background(), env, tracer)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	// create std db from pgxpool for the stats section
-	sqldb := stdlib.OpenDBFromPool(dbpool, stdlib.OptionBeforeConnect(func(ctx context.Context, cc *pgx.ConnConfig) error {
-		if !env.PostgresSecureMode {
-			cc.TLSConfig = &tls.Config{InsecureSkipVerify: true}
-		}
-		return nil
-	}))
+	sqldb := stdlib.OpenDBFromPool(dbpool)
 
 	return sqldb, dbpool, nil
 }
@@ -0,0 +1,102 @@
+// Package dbtest gives tests disposable Postgres databases. They are created on the server
+// of the POSTGRES_HOST, POSTGRES_PORT, POSTGRES_USER, POSTGRES_PASSWORD, POSTGRES_DATABASE
+// and POSTGRES_SSL_MODE variables, whose user must be allowed to create databases. Tests
+// needing one are skipped when POSTGRES_HOST is not set.
+package dbtest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/url"
+	"os"
+	"testing"
+
+	"connector-recruitment/go-server/connectors/config"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Env returns the settings of the test server, and skips t when there is none.
+func Env(t testing.TB) config.Env {
+	t.Helper()
+	host := os.Getenv("POSTGRES_HOST")
+	if host == "" {
+		t.Skip("POSTGRES_HOST is not set, skipping the tests needing postgres")
+	}
+	return config.Env{
+		Storage:                       config.StoragePostgres,
+		PostgresHost:                  host,
+		PostgresPort:                  getenv("POSTGRES_PORT", "5432"),
+		PostgresUser:                  os.Getenv("POSTGRES_USER"),
+		PostgresPassword:              os.Getenv("POSTGRES_PASSWORD"),
+		PostgresDatabase:              getenv("POSTGRES_DATABASE", "postgres"),
+		PostgresSSLMode:               getenv("POSTGRES_SSL_MODE", "disable"),
+		PostgresPoolSize:              4,
+		PostgresPoolMaxConnLifetime:   3600,
+		PostgresPoolMaxConnIdleTime:   1800,
+		PostgresPoolHealthCheckPeriod: 60,
+	}
+}
+
+// NewDatabase creates an empty database on the test server, dropped with everything in it
+// when t ends, and returns the settings connecting to it.
+func NewDatabase(t testing.TB) config.Env {
+	t.Helper()
+	env := Env(t)
+	ctx := context.Background()
+
+	admin, err := pgx.Connect(ctx, URL(env))
+	if err != nil {
+		t.Fatalf("failed to connect to the test server: %v", err)
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		t.Fatal(err)
+	}
+	env.PostgresDatabase = "test_" + hex.EncodeToString(suffix)
+	dbname := pgx.Identifier{env.PostgresDatabase}.Sanitize()
+	if _, err := admin.Exec(ctx, "CREATE DATABASE "+dbname); err != nil {
+		admin.Close(ctx)
+		t.Fatalf("failed to create database %s: %v", env.PostgresDatabase, err)
+	}
+	t.Cleanup(func() {
+		defer admin.Close(ctx)
+		if _, err := admin.Exec(ctx, "DROP DATABASE IF EXISTS "+dbname+" WITH (FORCE)"); err != nil {
+			t.Errorf("failed to drop database %s: %v", env.PostgresDatabase, err)
+		}
+	})
+	return env
+}
+
+// NewPool opens a pool of connections to the database of env, closed when t ends.
+func NewPool(t testing.TB, env config.Env) *pgxpool.Pool {
+	t.Helper()
+	pool, err := pgxpool.New(context.Background(), URL(env))
+	if err != nil {
+		t.Fatalf("failed to connect to database %s: %v", env.PostgresDatabase, err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// URL is the connection string of the database of env.
+func URL(env config.Env) string {
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(env.PostgresUser, env.PostgresPassword),
+		Host:     net.JoinHostPort(env.PostgresHost, env.PostgresPort),
+		Path:     "/" + env.PostgresDatabase,
+		RawQuery: url.Values{"sslmode": {env.PostgresSSLMode}}.Encode(),
+	}
+	return dsn.String()
+}
+
+func getenv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+
+	"connector-recruitment/sql/migrations"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// NewMigrator returns a migrate.Migrate running the embedded migrations against the
+// database. It has its own *sql.DB on top of the pool, so closing it leaves the pool open.
+// The caller must Close it.
+func (s *Service) NewMigrator() (*migrate.Migrate, error) {
+	return newMigrator(s.DBPool, database)
+}
+
+func newMigrator(pool *pgxpool.Pool, dbname string) (*migrate.Migrate, error) {
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+	driver, err := pgx.WithInstance(stdlib.OpenDBFromPool(pool), &pgx.Config{DatabaseName: dbname})
+	if err != nil {
+		src.Close()
+		return nil, fmt.Errorf("failed to open migration driver: %w", err)
+	}
+	m, err := migrate.NewWithInstance("iofs", src, dbname, driver)
+	if err != nil {
+		src.Close()
+		driver.Close()
+		return nil, fmt.Errorf("failed to create migrator: %w", err)
+	}
+	m.Log = migrateLogger{}
+	return m, nil
+}
+
+// Migrate applies every pending migration. Concurrent servers wait for each other on the
+// migration lock.
+func (s *Service) Migrate() error {
+	return MigratePool(s.DBPool, database)
+}
+
+// MigratePool applies every pending migration to the database dbname of pool.
+func MigratePool(pool *pgxpool.Pool, dbname string) error {
+	m, err := newMigrator(pool, dbname)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	return nil
+}
+
+// MigrationVersions lists the versions of the embedded migrations, in order.
+func MigrationVersions() ([]uint, error) {
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+	defer src.Close()
+
+	var versions []uint
+	version, err := src.First()
+	for err == nil {
+		versions = append(versions, version)
+		version, err = src.Next(version)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// migrateLogger prints the applied migrations.
+type migrateLogger struct{}
+
+func (migrateLogger) Printf(format string, v ...any) {
+	log.Printf("migrate: "+format, v...)
+}
+
+func (migrateLogger) Verbose() bool {
+	return false
+}
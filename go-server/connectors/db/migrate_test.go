@@ -0,0 +1,211 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"connector-recruitment/go-server/connectors/db/dbtest"
+	"connector-recruitment/sql/migrations"
+
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// schemaQuery lists the objects created by the migrations, so that two states of the
+// schema can be compared. The migrations table and the functions of extensions, which the
+// down migrations keep, are left out.
+const schemaQuery = `
+	SELECT 'column ' || table_name || '.' || column_name || ' ' || data_type || ' ' || is_nullable || ' ' || coalesce(column_default, '')
+	FROM information_schema.columns
+	WHERE table_schema = current_schema() AND table_name <> 'schema_migrations'
+	UNION ALL
+	SELECT 'index ' || indexdef
+	FROM pg_indexes
+	WHERE schemaname = current_schema() AND tablename <> 'schema_migrations'
+	UNION ALL
+	SELECT 'constraint ' || conrelid::regclass || '.' || conname || ' ' || pg_get_constraintdef(oid)
+	FROM pg_constraint
+	WHERE connamespace = current_schema()::regnamespace AND conname <> 'schema_migrations_pkey'
+	UNION ALL
+	SELECT 'function ' || proname
+	FROM pg_proc p
+	WHERE pronamespace = current_schema()::regnamespace
+		AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.objid = p.oid AND d.deptype = 'e')
+	UNION ALL
+	SELECT 'trigger ' || tgrelid::regclass || '.' || tgname
+	FROM pg_trigger
+	WHERE NOT tgisinternal
+	ORDER BY 1`
+
+// TestMigrations proves that the embedded migrations are reversible and idempotent. In a
+// disposable database it, for every migration in order:
+//
+//   - applies it, then runs its up script a second time, which must change nothing;
+//   - reverts it, which must restore the schema it found, then runs its down script a
+//     second time, which must change nothing;
+//   - applies it again, which must give the same schema as the first time.
+//
+// It ends with all migrations down, which must leave the schema as it was before the
+// first one, and up again.
+func TestMigrations(t *testing.T) {
+	env := dbtest.NewDatabase(t)
+	pool := dbtest.NewPool(t, env)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	v := &verifier{ctx: ctx, pool: pool, logf: t.Logf}
+	if err := v.run(env.PostgresDatabase); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type verifier struct {
+	ctx  context.Context
+	pool *pgxpool.Pool
+	logf func(format string, args ...any)
+}
+
+func (v *verifier) run(dbname string) error {
+	versions, err := MigrationVersions()
+	if err != nil {
+		return err
+	}
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+	defer src.Close()
+
+	m, err := newMigrator(v.pool, dbname)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	initial, err := v.schema()
+	if err != nil {
+		return err
+	}
+
+	before := initial
+	for _, version := range versions {
+		upSQL, upName, err := readMigration(src.ReadUp, version)
+		if err != nil {
+			return err
+		}
+		downSQL, _, err := readMigration(src.ReadDown, version)
+		if err != nil {
+			return err
+		}
+
+		if err := m.Steps(1); err != nil {
+			return fmt.Errorf("%s: up failed: %w", upName, err)
+		}
+		after, err := v.schema()
+		if err != nil {
+			return err
+		}
+		if err := v.expect(upName, "up run twice", upSQL, after); err != nil {
+			return err
+		}
+
+		if err := m.Steps(-1); err != nil {
+			return fmt.Errorf("%s: down failed: %w", upName, err)
+		}
+		if err := v.expect(upName, "down", "", before); err != nil {
+			return err
+		}
+		if err := v.expect(upName, "down run twice", downSQL, before); err != nil {
+			return err
+		}
+
+		if err := m.Steps(1); err != nil {
+			return fmt.Errorf("%s: up after down failed: %w", upName, err)
+		}
+		if err := v.expect(upName, "up after down", "", after); err != nil {
+			return err
+		}
+		v.logf("%s: reversible and idempotent", upName)
+		before = after
+	}
+
+	if err := m.Down(); err != nil {
+		return fmt.Errorf("down all failed: %w", err)
+	}
+	if err := v.expect("all migrations", "down all", "", initial); err != nil {
+		return err
+	}
+	if err := m.Up(); err != nil {
+		return fmt.Errorf("up all failed: %w", err)
+	}
+	if err := v.expect("all migrations", "up all", "", before); err != nil {
+		return err
+	}
+	v.logf("%d migrations verified", len(versions))
+	return nil
+}
+
+// expect runs script, when there is one, and compares the schema with want.
+func (v *verifier) expect(name, step, script string, want []string) error {
+	if script != "" {
+		if _, err := v.pool.Exec(v.ctx, script); err != nil {
+			return fmt.Errorf("%s: %s failed: %w", name, step, err)
+		}
+	}
+	got, err := v.schema()
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(got, want) {
+		return fmt.Errorf("%s: %s left a different schema:\n%s", name, step, schemaDiff(want, got))
+	}
+	return nil
+}
+
+func (v *verifier) schema() ([]string, error) {
+	rows, err := v.pool.Query(v.ctx, schemaQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+func readMigration(read func(uint) (io.ReadCloser, string, error), version uint) (string, string, error) {
+	r, name, err := read(version)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", "", fmt.Errorf("migration %d has no up or down script", version)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	defer r.Close()
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return "", "", err
+	}
+	return string(b), fmt.Sprintf("%d_%s", version, name), nil
+}
+
+// schemaDiff lists the objects missing from got with -, and the unexpected ones with +.
+func schemaDiff(want, got []string) string {
+	var b strings.Builder
+	for _, w := range want {
+		if !slices.Contains(got, w) {
+			fmt.Fprintf(&b, "  - %s\n", w)
+		}
+	}
+	for _, g := range got {
+		if !slices.Contains(want, g) {
+			fmt.Fprintf(&b, "  + %s\n", g)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// primaryLSNQuery is the current WAL position of the primary.
+const primaryLSNQuery = `SELECT pg_current_wal_lsn()::text`
+
+// replicaStateQuery tells, given the WAL position of the primary read just before, whether
+// the replica is a standby, whether its WAL receiver is running and streaming (users
+// without pg_read_all_stats only see that it runs), whether it replayed the WAL up to that
+// position, and how long ago it replayed its last transaction, in seconds.
+const replicaStateQuery = `
+	SELECT
+		pg_is_in_recovery(),
+		EXISTS (SELECT 1 FROM pg_stat_wal_receiver WHERE status IS NULL OR status = 'streaming'),
+		COALESCE(pg_last_wal_replay_lsn() >= $1::pg_lsn, false),
+		EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())::float8`
+
+// Replica is a read replica of the database. It is checked periodically, and only handed
+// out while it answers and its replication lag is within the configured maximum.
+type Replica struct {
+	primary *pgxpool.Pool
+	pool    *pgxpool.Pool
+	maxLag  time.Duration
+
+	usable atomic.Bool
+	lag    atomic.Int64 // nanoseconds
+	err    atomic.Value // string, the reason the replica is not usable
+
+	stop     context.CancelFunc
+	stopped  sync.WaitGroup
+	interval time.Duration
+}
+
+func newReplica(primary, pool *pgxpool.Pool, maxLag, interval time.Duration) *Replica {
+	r := &Replica{primary: primary, pool: pool, maxLag: maxLag, interval: interval}
+	r.err.Store("not checked yet")
+	return r
+}
+
+// Pool returns the replica pool, or nil when the replica is unhealthy or lagging and reads
+// must go to the primary. It is safe to call on a nil Replica.
+func (r *Replica) Pool() *pgxpool.Pool {
+	if r == nil || !r.usable.Load() {
+		return nil
+	}
+	return r.pool
+}
+
+// start checks the replica once, then every interval until Close.
+func (r *Replica) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.stop = cancel
+	r.check(ctx)
+
+	r.stopped.Add(1)
+	go func() {
+		defer r.stopped.Done()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.check(ctx)
+			}
+		}
+	}()
+}
+
+func (r *Replica) check(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 1*time.Second)
+	defer cancel()
+
+	lag, err := r.replicationLag(ctx)
+	if err == nil {
+		r.lag.Store(int64(lag))
+		if lag > r.maxLag {
+			err = fmt.Errorf("replication lag %s exceeds %s", lag.Round(time.Millisecond), r.maxLag)
+		}
+	}
+
+	if err != nil {
+		if r.usable.Swap(false) {
+			log.Printf("Read replica disabled, reads go to the primary: %v", err)
+		}
+		r.err.Store(err.Error())
+		return
+	}
+	if !r.usable.Swap(true) {
+		log.Printf("Read replica enabled")
+	}
+	r.err.Store("")
+}
+
+// replicationLag is how far the replica is behind the primary. A replica that replayed the
+// WAL up to the current position of the primary has no lag, however old its last
+// transaction; one that does not receive the WAL anymore is an error, since it cannot
+// catch up. A database that is not a standby has no lag either.
+func (r *Replica) replicationLag(ctx context.Context) (time.Duration, error) {
+	var primaryLSN string
+	if err := r.primary.QueryRow(ctx, primaryLSNQuery).Scan(&primaryLSN); err != nil {
+		return 0, fmt.Errorf("failed to read the WAL position of the primary: %w", err)
+	}
+
+	var (
+		standby, receiving, caughtUp bool
+		seconds                      *float64
+	)
+	err := r.pool.QueryRow(ctx, replicaStateQuery, primaryLSN).Scan(&standby, &receiving, &caughtUp, &seconds)
+	switch {
+	case err != nil:
+		return 0, err
+	case !standby:
+		return 0, nil
+	case !receiving:
+		return 0, errors.New("the WAL receiver of the replica is not streaming")
+	case caughtUp:
+		return 0, nil
+	case seconds == nil:
+		return 0, errors.New("the replica has not replayed any transaction yet")
+	}
+	return time.Duration(*seconds * float64(time.Second)), nil
+}
+
+// Health reports the state of the replica, in the format of Service.Health.
+func (r *Replica) Health() map[string]string {
+	stats := map[string]string{
+		"status": "up",
+		"lag":    time.Duration(r.lag.Load()).String(),
+	}
+	if !r.usable.Load() {
+		stats["status"] = "down"
+		stats["error"] = r.err.Load().(string)
+	}
+	return stats
+}
+
+// Close stops the checks and closes the replica pool.
+func (r *Replica) Close() {
+	if r.stop != nil {
+		r.stop()
+		r.stopped.Wait()
+	}
+	r.pool.Close()
+}
@@ -0,0 +1,146 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"connector-recruitment/go-server/connectors/config"
+	"connector-recruitment/go-server/connectors/logger"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName names the spans of the queries in the TracerProvider the server registers
+// with POSTGRES_TRACING.
+const tracerName = "connector-recruitment/go-server/connectors/db"
+
+// queryTracer logs the queries run on a pool, and attaches a span to each of them. Query
+// arguments are never logged, nor attached to spans: they may hold tokens.
+type queryTracer struct {
+	logger logger.Logger
+	// debug logs every query, at debug level.
+	debug bool
+	// slow is the duration beyond which a query is logged as a warning; 0 never does.
+	slow time.Duration
+	// tracer is nil when tracing is disabled.
+	tracer trace.Tracer
+	// database names the database of the spans: the same on the primary and its replica.
+	database string
+}
+
+// newQueryTracer returns the tracer of the queries of env, or nil when it would do nothing.
+func newQueryTracer(env config.Env, log logger.Logger) pgx.QueryTracer {
+	slow := time.Duration(env.PostgresSlowQueryThreshold) * time.Millisecond
+	if !env.PostgresDebug && slow <= 0 && !env.PostgresTracing {
+		return nil
+	}
+	t := &queryTracer{logger: log, debug: env.PostgresDebug, slow: slow, database: env.PostgresDatabase}
+	if env.PostgresTracing {
+		t.tracer = otel.Tracer(tracerName)
+	}
+	return t
+}
+
+type queryTraceKey struct{}
+
+type queryTrace struct {
+	name  string
+	sql   string
+	start time.Time
+	span  trace.Span
+}
+
+func (t *queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	q := &queryTrace{name: queryName(data.SQL), sql: data.SQL, start: time.Now()}
+	if t.tracer != nil {
+		ctx, q.span = t.tracer.Start(ctx, q.name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", "postgresql"),
+				attribute.String("db.name", t.database),
+				attribute.String("db.statement", compactSQL(data.SQL)),
+			))
+	}
+	return context.WithValue(ctx, queryTraceKey{}, q)
+}
+
+func (t *queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	q, ok := ctx.Value(queryTraceKey{}).(*queryTrace)
+	if !ok {
+		return
+	}
+	duration := time.Since(q.start)
+	rows := data.CommandTag.RowsAffected()
+
+	if q.span != nil {
+		q.span.SetAttributes(attribute.Int64("db.rows_affected", rows))
+		if data.Err != nil {
+			q.span.RecordError(data.Err)
+			q.span.SetStatus(codes.Error, data.Err.Error())
+		}
+		q.span.End()
+	}
+
+	kv := []any{"query", q.name, "duration", duration, "rows", rows}
+	if data.Err != nil {
+		kv = append(kv, "error", data.Err)
+	}
+	switch {
+	case t.slow > 0 && duration >= t.slow:
+		t.logger.Warn("slow query", append(kv, "sql", compactSQL(q.sql))...)
+	case t.debug:
+		t.logger.Debug("query", append(kv, "sql", compactSQL(q.sql))...)
+	}
+}
+
+// queryName names a query by its statement and the table it touches, like "SELECT
+// connectors" or "UPDATE connectors".
+func queryName(sql string) string {
+	words := strings.Fields(sql)
+	if len(words) == 0 {
+		return "unknown"
+	}
+	verb := strings.ToUpper(words[0])
+	after := ""
+	switch verb {
+	case "SELECT", "DELETE":
+		after = "FROM"
+	case "INSERT":
+		after = "INTO"
+	case "UPDATE":
+		if len(words) > 1 {
+			return withTable(verb, words[1])
+		}
+		return verb
+	default:
+		return verb
+	}
+	for i, w := range words[:len(words)-1] {
+		if strings.EqualFold(w, after) {
+			return withTable(verb, words[i+1])
+		}
+	}
+	return verb
+}
+
+// withTable appends to verb the table name starting word, without the column list or
+// punctuation following it. A subquery has no name.
+func withTable(verb, word string) string {
+	if i := strings.IndexAny(word, "(;,"); i >= 0 {
+		word = word[:i]
+	}
+	if word == "" {
+		return verb
+	}
+	return verb + " " + word
+}
+
+// compactSQL puts a query on one line.
+func compactSQL(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
@@ -36,3 +36,68 @@ func (e *ConnectorExistAlreadyError) Error() string {
 func NewConnectorAlreadyExistError(ID string) error {
 	return fmt.Errorf("%w: %s", ErrConnectorExistAlready, &ConnectorExistAlreadyError{ID: ID})
 }
+
+// ErrRoutingRuleNotFound is the base error for not found routing rules
+var ErrRoutingRuleNotFound = errors.New("routing rule not found")
+
+type RoutingRuleNotFoundError struct {
+	ID string
+}
+
+func (e *RoutingRuleNotFoundError) Error() string {
+	return fmt.Sprintf("routing rule with ID %s not found", e.ID)
+}
+
+// NewRoutingRuleNotFoundError creates a new error with the given ID
+func NewRoutingRuleNotFoundError(ID string) error {
+	return fmt.Errorf("%w: %s", ErrRoutingRuleNotFound, &RoutingRuleNotFoundError{ID: ID})
+}
+
+// ErrInvalidRouteTarget is returned when a routing rule targets a connector that does not
+// exist or belongs to another tenant
+var ErrInvalidRouteTarget = errors.New("invalid route target")
+
+// NewInvalidRouteTargetError creates a new error for the given connector ID and reason
+func NewInvalidRouteTargetError(connectorID, reason string) error {
+	return fmt.Errorf("%w: connector %s %s", ErrInvalidRouteTarget, connectorID, reason)
+}
+
+// ErrInvalidPageToken is returned when a page token cannot be decoded
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// ErrConnectorSuspended is returned when sending through a connector that was suspended
+// after a permanent failure
+var ErrConnectorSuspended = errors.New("connector suspended")
+
+// NewConnectorSuspendedError creates a new error for the given connector ID and suspension reason
+func NewConnectorSuspendedError(ID, reason string) error {
+	return fmt.Errorf("%w: connector %s: %s", ErrConnectorSuspended, ID, reason)
+}
+
+// ErrCircuitOpen is returned when a connector failed too many times in a row and sends
+// are rejected until its circuit breaker cools down
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// ErrHealthProbeUnsupported is returned when probing a connector whose type has no health check
+var ErrHealthProbeUnsupported = errors.New("health probe not supported")
+
+// ErrVersionMismatch is returned when a connector changed since the version the caller
+// read, so that concurrent edits do not overwrite each other
+var ErrVersionMismatch = errors.New("version mismatch")
+
+type VersionMismatchError struct {
+	ID      string
+	Current int64
+}
+
+func (e *VersionMismatchError) Error() string {
+	return fmt.Sprintf("connector %s is at version %d", e.ID, e.Current)
+}
+
+// NewVersionMismatchError creates a new error with the given ID and current version
+func NewVersionMismatchError(ID string, current int64) error {
+	return fmt.Errorf("%w: %w", ErrVersionMismatch, &VersionMismatchError{ID: ID, Current: current})
+}
+
+// ErrInvalidEtag is returned when an etag precondition cannot be parsed
+var ErrInvalidEtag = errors.New("invalid etag")
@@ -0,0 +1,41 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrSecretNotFound is the base error for not found secrets
+var ErrSecretNotFound = errors.New("secret not found")
+
+// NewSecretNotFoundError creates a new error with the given secret name
+func NewSecretNotFoundError(name string) error {
+	return fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+}
+
+// ErrSecretExistAlready is returned when creating a secret under a name that is taken
+var ErrSecretExistAlready = errors.New("secret exist already")
+
+// NewSecretAlreadyExistError creates a new error with the given secret name
+func NewSecretAlreadyExistError(name string) error {
+	return fmt.Errorf("%w: %s", ErrSecretExistAlready, name)
+}
+
+// ErrNoPreviousSecretVersion is returned when rolling back a secret that was never rotated
+var ErrNoPreviousSecretVersion = errors.New("no previous secret version")
+
+// NewNoPreviousSecretVersionError creates a new error with the given secret name
+func NewNoPreviousSecretVersionError(name string) error {
+	return fmt.Errorf("%w: %s", ErrNoPreviousSecretVersion, name)
+}
+
+// ErrNoSecret is returned when changing the secret of a connector that has none
+var ErrNoSecret = errors.New("connector has no secret")
+
+// NewNoSecretError creates a new error with the given connector ID
+func NewNoSecretError(connectorID string) error {
+	return fmt.Errorf("%w: %s", ErrNoSecret, connectorID)
+}
+
+// ErrTokenValidationFailed is returned when a new token is rejected before being stored
+var ErrTokenValidationFailed = errors.New("token validation failed")
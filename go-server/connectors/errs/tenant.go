@@ -0,0 +1,30 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTenantNotFound is the base error for not found tenants
+var ErrTenantNotFound = errors.New("tenant not found")
+
+// NewTenantNotFoundError creates a new error with the given tenant ID
+func NewTenantNotFoundError(ID string) error {
+	return fmt.Errorf("%w: %s", ErrTenantNotFound, ID)
+}
+
+// ErrTenantExistAlready is returned when creating a tenant under an ID that is taken
+var ErrTenantExistAlready = errors.New("tenant exist already")
+
+// NewTenantAlreadyExistError creates a new error with the given tenant ID
+func NewTenantAlreadyExistError(ID string) error {
+	return fmt.Errorf("%w: %s", ErrTenantExistAlready, ID)
+}
+
+// ErrTenantSuspended is returned when creating a connector for a suspended tenant
+var ErrTenantSuspended = errors.New("tenant suspended")
+
+// NewTenantSuspendedError creates a new error with the given tenant ID
+func NewTenantSuspendedError(ID string) error {
+	return fmt.Errorf("%w: %s", ErrTenantSuspended, ID)
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"connector-recruitment/go-server/connectors/errs"
+
+	errdetails "google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+)
+
+// etagError maps the failures of etag preconditions to gRPC statuses. A stale etag is
+// ABORTED, with the connector's current etag in the ErrorInfo metadata so that the caller
+// can read the connector again and retry. ok is false for other errors.
+func (h *ConnectorsGrpcHandler) etagError(method, connectorID string, err error) (st error, ok bool) {
+	var mismatch *errs.VersionMismatchError
+	switch {
+	case errors.As(err, &mismatch):
+		h.logger.Warn(method+" stale etag", "id", connectorID, "current-version", mismatch.Current)
+		// etags are the decimal connector version.
+		return h.errorWithInfo(method, codes.Aborted, fmt.Sprintf("connector with id %s was modified concurrently", connectorID),
+			"EtagMismatch", map[string]string{
+				"connectorId": connectorID,
+				"currentEtag": strconv.FormatInt(mismatch.Current, 10),
+			}), true
+	case errors.Is(err, errs.ErrInvalidEtag):
+		return h.invalidArgument(method, []*errdetails.BadRequest_FieldViolation{{
+			Field:       "etag",
+			Description: err.Error(),
+		}}), true
+	}
+	return nil, false
+}
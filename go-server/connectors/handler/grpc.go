@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/mail"
+	"net/url"
 
 	"connector-recruitment/go-server/connectors/errs"
 	pb "connector-recruitment/go-server/connectors/genproto"
@@ -15,8 +17,12 @@ import (
 	"google.golang.org/grpc/status"
 
 	errdetails "google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/protoadapt"
 )
 
+// maxDigestWindowSeconds is the longest a digest connector may hold messages back.
+const maxDigestWindowSeconds = 24 * 60 * 60
+
 // ConnectorsGrpcHandler implements pb.ConnectorServiceServer.
 type ConnectorsGrpcHandler struct {
 	logger           logger.Logger
@@ -53,7 +59,7 @@ func (h *ConnectorsGrpcHandler) GetConnector(ctx context.Context, req *pb.GetCon
 		return nil, stWithDetails.Err()
 	}
 
-	conn, err := h.connectorService.GetConnector(ctx, req.ConnectorId)
+	conn, err := h.connectorService.GetConnector(ctx, req.ConnectorId, req.ReadYourWrites)
 	if err != nil {
 		if errors.Is(err, errs.ErrConnectorNotFound) {
 			h.logger.Warn("GetConnector not found", "id", req.ConnectorId)
@@ -95,22 +101,58 @@ func (h *ConnectorsGrpcHandler) GetConnector(ctx context.Context, req *pb.GetCon
 func (h *ConnectorsGrpcHandler) CreateConnector(ctx context.Context, req *pb.CreateConnectorRequest) (*pb.CreateConnectorResponse, error) {
 	// Validate inputs and build up a list of field violations.
 	var violations []*errdetails.BadRequest_FieldViolation
-	if len(req.SlackToken) == 0 {
-		violations = append(violations, &errdetails.BadRequest_FieldViolation{
-			Field:       "slackToken",
-			Description: "slack token is required",
-		})
-	}
 	if len(req.TenantId) == 0 {
 		violations = append(violations, &errdetails.BadRequest_FieldViolation{
 			Field:       "tenantId",
 			Description: "tenant id is required",
 		})
 	}
-	if len(req.DefaultChannelId) == 0 {
+	secret := req.SlackToken
+	switch req.Type {
+	case pb.ConnectorType_CONNECTOR_TYPE_UNSPECIFIED, pb.ConnectorType_CONNECTOR_TYPE_SLACK:
+		if len(req.SlackToken) == 0 {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       "slackToken",
+				Description: "slack token is required",
+			})
+		}
+		if len(req.DefaultChannelId) == 0 {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       "defaultChannelId",
+				Description: "default channel id is required",
+			})
+		}
+	case pb.ConnectorType_CONNECTOR_TYPE_WEBHOOK:
+		secret = req.WebhookSecret
+		violations = append(violations, validateWebhookConfig(req.Webhook)...)
+		if len(req.WebhookSecret) == 0 {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       "webhookSecret",
+				Description: "webhook secret is required",
+			})
+		}
+	case pb.ConnectorType_CONNECTOR_TYPE_EMAIL:
+		// without a username the client does not authenticate, so no secret is stored
+		if req.Email.GetUsername() != "" {
+			secret = req.SmtpPassword
+		}
+		violations = append(violations, validateEmailConfig(req.Email)...)
+		if req.Email.GetUsername() != "" && len(req.SmtpPassword) == 0 {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       "smtpPassword",
+				Description: "smtp password is required when a username is set",
+			})
+		}
+	default:
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "type",
+			Description: fmt.Sprintf("unsupported connector type: %s", req.Type),
+		})
+	}
+	if dg := req.Digest; dg != nil && (dg.WindowSeconds < 0 || dg.WindowSeconds > maxDigestWindowSeconds) {
 		violations = append(violations, &errdetails.BadRequest_FieldViolation{
-			Field:       "defaultChannelId",
-			Description: "default channel id is required",
+			Field:       "digest.windowSeconds",
+			Description: fmt.Sprintf("digest window must be between 0 and %d seconds", maxDigestWindowSeconds),
 		})
 	}
 	if len(violations) > 0 {
@@ -124,10 +166,25 @@ func (h *ConnectorsGrpcHandler) CreateConnector(ctx context.Context, req *pb.Cre
 		return nil, stWithDetails.Err()
 	}
 
-	err := h.connectorService.CreateConnector(ctx, req.SlackToken, &pb.Connector{
+	err := h.connectorService.CreateConnector(ctx, secret, &pb.Connector{
 		TenantId:         req.TenantId,
 		DefaultChannelId: req.DefaultChannelId,
+		Type:             req.Type,
+		Webhook:          req.Webhook,
+		Email:            req.Email,
+		Digest:           req.Digest,
 	})
+	// The tenant must exist and be active: a precondition of the connector, not a missing resource.
+	if errors.Is(err, errs.ErrTenantNotFound) {
+		h.logger.Warn("CreateConnector unknown tenant", "tenant", req.TenantId)
+		return nil, h.errorWithInfo("CreateConnector", codes.FailedPrecondition,
+			fmt.Sprintf("tenant with id %s not found", req.TenantId), "TenantNotFound", map[string]string{"tenantId": req.TenantId})
+	}
+	if errors.Is(err, errs.ErrTenantSuspended) {
+		h.logger.Warn("CreateConnector suspended tenant", "tenant", req.TenantId)
+		return nil, h.errorWithInfo("CreateConnector", codes.FailedPrecondition,
+			fmt.Sprintf("tenant with id %s is suspended", req.TenantId), "TenantSuspended", map[string]string{"tenantId": req.TenantId})
+	}
 	if err != nil {
 		h.logger.Error("CreateConnector internal error", "err", err.Error())
 		info := &errdetails.ErrorInfo{
@@ -148,7 +205,7 @@ func (h *ConnectorsGrpcHandler) CreateConnector(ctx context.Context, req *pb.Cre
 }
 
 func (h *ConnectorsGrpcHandler) GetConnectors(ctx context.Context, req *pb.GetConnectorsRequest) (*pb.GetConnectorsResponse, error) {
-	conns := h.connectorService.GetConnectors(ctx)
+	conns := h.connectorService.GetConnectors(ctx, req.ReadYourWrites)
 	res := &pb.GetConnectorsResponse{
 		Connectors: conns,
 	}
@@ -156,8 +213,11 @@ func (h *ConnectorsGrpcHandler) GetConnectors(ctx context.Context, req *pb.GetCo
 }
 
 func (h *ConnectorsGrpcHandler) DeleteConnector(ctx context.Context, req *pb.DeleteConnectorRequest) (*pb.DeleteConnectorResponse, error) {
-	err := h.connectorService.DeleteConnector(ctx, req.ConnectorId)
+	err := h.connectorService.DeleteConnector(ctx, req.ConnectorId, req.Etag)
 	if err != nil {
+		if st, ok := h.etagError("DeleteConnector", req.ConnectorId, err); ok {
+			return nil, st
+		}
 		if errors.Is(err, errs.ErrConnectorNotFound) {
 			h.logger.Warn("DeleteConnector not found", "id", req.ConnectorId)
 			info := &errdetails.ErrorInfo{
@@ -191,3 +251,219 @@ func (h *ConnectorsGrpcHandler) DeleteConnector(ctx context.Context, req *pb.Del
 
 	return &pb.DeleteConnectorResponse{}, nil
 }
+
+func (h *ConnectorsGrpcHandler) SendMessage(ctx context.Context, req *pb.SendMessageRequest) (*pb.SendMessageResponse, error) {
+	var violations []*errdetails.BadRequest_FieldViolation
+	if req.ConnectorId == "" {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "connectorId",
+			Description: "missing required field: connectorId",
+		})
+	}
+	if req.Message == "" {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "message",
+			Description: "missing required field: message",
+		})
+	}
+	if _, ok := pb.MessagePriority_name[int32(req.Priority)]; !ok {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "priority",
+			Description: fmt.Sprintf("unsupported message priority: %d", req.Priority),
+		})
+	}
+	if _, ok := pb.MessageFormat_name[int32(req.Format)]; !ok {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "format",
+			Description: fmt.Sprintf("unsupported message format: %d", req.Format),
+		})
+	}
+	if len(violations) > 0 {
+		br := &errdetails.BadRequest{FieldViolations: violations}
+		st := status.New(codes.InvalidArgument, "invalid input parameters")
+		stWithDetails, err := st.WithDetails(br)
+		if err != nil {
+			h.logger.Error("SendMessage: failed to attach bad request details", "error", err)
+			return nil, st.Err()
+		}
+		return nil, stWithDetails.Err()
+	}
+
+	delivery, err := h.connectorService.SendMessage(ctx, req)
+	if err != nil {
+		if errors.Is(err, errs.ErrConnectorNotFound) {
+			h.logger.Warn("SendMessage connector not found", "id", req.ConnectorId)
+			info := &errdetails.ErrorInfo{
+				Reason:   "ConnectorNotFound",
+				Domain:   "connectors.service",
+				Metadata: map[string]string{"connectorId": req.ConnectorId},
+			}
+			st := status.New(codes.NotFound, fmt.Sprintf("connector with id %s not found", req.ConnectorId))
+			stWithDetails, detailsErr := st.WithDetails(info)
+			if detailsErr != nil {
+				h.logger.Error("SendMessage: failed to attach error details", "error", detailsErr)
+				return nil, st.Err()
+			}
+			return nil, stWithDetails.Err()
+		}
+
+		if errors.Is(err, errs.ErrConnectorSuspended) {
+			h.logger.Warn("SendMessage connector suspended", "id", req.ConnectorId, "err", err)
+			return nil, h.errorWithInfo("SendMessage", codes.FailedPrecondition, err.Error(), "ConnectorSuspended",
+				map[string]string{"connectorId": req.ConnectorId})
+		}
+		if errors.Is(err, errs.ErrCircuitOpen) {
+			h.logger.Warn("SendMessage circuit open", "id", req.ConnectorId, "err", err)
+			return nil, h.errorWithInfo("SendMessage", codes.Unavailable, err.Error(), "CircuitOpen",
+				map[string]string{"connectorId": req.ConnectorId})
+		}
+
+		h.logger.Error("SendMessage delivery failed", "id", req.ConnectorId, "err", err)
+		info := &errdetails.ErrorInfo{
+			Reason:   "DeliveryFailed",
+			Domain:   "connectors.service",
+			Metadata: map[string]string{"connectorId": req.ConnectorId},
+		}
+		details := []protoadapt.MessageV1{info}
+		if delivery != nil {
+			// the parts of a split message posted before the failure
+			details = append(details, delivery)
+		}
+		st := status.New(codes.Unavailable, "failed to deliver message")
+		stWithDetails, detailsErr := st.WithDetails(details...)
+		if detailsErr != nil {
+			h.logger.Error("SendMessage: failed to attach error details", "error", detailsErr)
+			return nil, st.Err()
+		}
+		return nil, stWithDetails.Err()
+	}
+
+	return &pb.SendMessageResponse{Delivery: delivery}, nil
+}
+
+func (h *ConnectorsGrpcHandler) BroadcastMessage(ctx context.Context, req *pb.BroadcastMessageRequest) (*pb.BroadcastMessageResponse, error) {
+	var violations []*errdetails.BadRequest_FieldViolation
+	if (req.TenantId == "") == (len(req.ConnectorIds) == 0) {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "tenantId",
+			Description: "exactly one of tenantId or connectorIds is required",
+		})
+	}
+	for i, id := range req.ConnectorIds {
+		if id == "" {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       fmt.Sprintf("connectorIds[%d]", i),
+				Description: "connector id must not be empty",
+			})
+		}
+	}
+	if req.Message == "" {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "message",
+			Description: "missing required field: message",
+		})
+	}
+	if len(violations) > 0 {
+		br := &errdetails.BadRequest{FieldViolations: violations}
+		st := status.New(codes.InvalidArgument, "invalid input parameters")
+		stWithDetails, err := st.WithDetails(br)
+		if err != nil {
+			h.logger.Error("BroadcastMessage: failed to attach bad request details", "error", err)
+			return nil, st.Err()
+		}
+		return nil, stWithDetails.Err()
+	}
+
+	results, err := h.connectorService.BroadcastMessage(ctx, req.TenantId, req.ConnectorIds, req.Message)
+	if err != nil {
+		h.logger.Error("BroadcastMessage internal error", "tenant", req.TenantId, "err", err)
+		info := &errdetails.ErrorInfo{
+			Reason:   "InternalError",
+			Domain:   "connectors.service",
+			Metadata: map[string]string{"tenantId": req.TenantId},
+		}
+		st := status.New(codes.Internal, "internal server error: failed to broadcast message")
+		stWithDetails, detailsErr := st.WithDetails(info)
+		if detailsErr != nil {
+			h.logger.Error("BroadcastMessage: failed to attach internal error details", "error", detailsErr)
+			return nil, st.Err()
+		}
+		return nil, stWithDetails.Err()
+	}
+
+	return &pb.BroadcastMessageResponse{Results: results}, nil
+}
+
+// validateWebhookConfig reports the field violations of a webhook connector's configuration.
+func validateWebhookConfig(cfg *pb.WebhookConfig) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
+	if cfg == nil || cfg.Url == "" {
+		return append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "webhook.url",
+			Description: "webhook url is required",
+		})
+	}
+	if u, err := url.Parse(cfg.Url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "webhook.url",
+			Description: "webhook url must be an absolute http(s) url",
+		})
+	}
+	if cfg.TimeoutSeconds < 0 {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "webhook.timeoutSeconds",
+			Description: "timeout must not be negative",
+		})
+	}
+	if cfg.MaxRetries < 0 || cfg.MaxRetries > 10 {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "webhook.maxRetries",
+			Description: "max retries must be between 0 and 10",
+		})
+	}
+	return violations
+}
+
+// validateEmailConfig reports the field violations of an email connector's configuration.
+func validateEmailConfig(cfg *pb.EmailConfig) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
+	if cfg == nil {
+		return append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "email",
+			Description: "email configuration is required",
+		})
+	}
+	if cfg.SmtpHost == "" {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "email.smtpHost",
+			Description: "smtp host is required",
+		})
+	}
+	if cfg.SmtpPort <= 0 || cfg.SmtpPort > 65535 {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "email.smtpPort",
+			Description: "smtp port must be between 1 and 65535",
+		})
+	}
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "email.from",
+			Description: "from must be a valid email address",
+		})
+	}
+	if len(cfg.To) == 0 {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "email.to",
+			Description: "at least one recipient is required",
+		})
+	}
+	for i, to := range cfg.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       fmt.Sprintf("email.to[%d]", i),
+				Description: "recipient must be a valid email address",
+			})
+		}
+	}
+	return violations
+}
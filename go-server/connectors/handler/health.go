@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"connector-recruitment/go-server/connectors/errs"
+	pb "connector-recruitment/go-server/connectors/genproto"
+
+	errdetails "google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+)
+
+func (h *ConnectorsGrpcHandler) GetConnectorHealth(ctx context.Context, req *pb.GetConnectorHealthRequest) (*pb.GetConnectorHealthResponse, error) {
+	if req.ConnectorId == "" {
+		return nil, h.invalidArgument("GetConnectorHealth", []*errdetails.BadRequest_FieldViolation{{
+			Field:       "connectorId",
+			Description: "missing required field: connectorId",
+		}})
+	}
+
+	health, err := h.connectorService.GetConnectorHealth(ctx, req.ConnectorId, req.Refresh)
+	if err != nil {
+		metadata := map[string]string{"connectorId": req.ConnectorId}
+		switch {
+		case errors.Is(err, errs.ErrConnectorNotFound):
+			h.logger.Warn("GetConnectorHealth connector not found", "id", req.ConnectorId)
+			return nil, h.errorWithInfo("GetConnectorHealth", codes.NotFound,
+				fmt.Sprintf("connector with id %s not found", req.ConnectorId), "ConnectorNotFound", metadata)
+		case errors.Is(err, errs.ErrHealthProbeUnsupported):
+			return nil, h.errorWithInfo("GetConnectorHealth", codes.FailedPrecondition, err.Error(),
+				"HealthProbeUnsupported", metadata)
+		default:
+			h.logger.Error("GetConnectorHealth internal error", "id", req.ConnectorId, "err", err)
+			return nil, h.errorWithInfo("GetConnectorHealth", codes.Internal,
+				"internal server error: failed to get connector health", "InternalError", metadata)
+		}
+	}
+	return &pb.GetConnectorHealthResponse{Health: health}, nil
+}
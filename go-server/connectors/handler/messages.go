@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"connector-recruitment/go-server/connectors/errs"
+	pb "connector-recruitment/go-server/connectors/genproto"
+
+	errdetails "google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+)
+
+func (h *ConnectorsGrpcHandler) ListMessages(ctx context.Context, req *pb.ListMessagesRequest) (*pb.ListMessagesResponse, error) {
+	var violations []*errdetails.BadRequest_FieldViolation
+	if req.PageSize < 0 {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "pageSize",
+			Description: "page size must not be negative",
+		})
+	}
+	if req.StartTime != nil && req.EndTime != nil && !req.StartTime.AsTime().Before(req.EndTime.AsTime()) {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "endTime",
+			Description: "end time must be after start time",
+		})
+	}
+	if len(violations) > 0 {
+		return nil, h.invalidArgument("ListMessages", violations)
+	}
+
+	messages, nextPageToken, err := h.connectorService.ListMessages(ctx, req)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidPageToken) {
+			return nil, h.invalidArgument("ListMessages", []*errdetails.BadRequest_FieldViolation{{
+				Field:       "pageToken",
+				Description: "invalid page token",
+			}})
+		}
+
+		h.logger.Error("ListMessages internal error", "err", err)
+		return nil, h.errorWithInfo("ListMessages", codes.Internal, "internal server error: failed to list messages",
+			"InternalError", map[string]string{})
+	}
+
+	return &pb.ListMessagesResponse{Messages: messages, NextPageToken: nextPageToken}, nil
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"connector-recruitment/go-server/connectors/errs"
+	pb "connector-recruitment/go-server/connectors/genproto"
+
+	errdetails "google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+)
+
+func (h *ConnectorsGrpcHandler) CreateRoutingRule(ctx context.Context, req *pb.CreateRoutingRuleRequest) (*pb.CreateRoutingRuleResponse, error) {
+	violations := validateRoutingRule(req.Rule)
+	if req.Rule.GetTenantId() == "" {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "rule.tenantId",
+			Description: "tenant id is required",
+		})
+	}
+	if len(violations) > 0 {
+		return nil, h.invalidArgument("CreateRoutingRule", violations)
+	}
+
+	rule, err := h.connectorService.CreateRoutingRule(ctx, req.Rule)
+	if errors.Is(err, errs.ErrTenantNotFound) {
+		h.logger.Warn("CreateRoutingRule unknown tenant", "tenant", req.Rule.TenantId)
+		return nil, h.errorWithInfo("CreateRoutingRule", codes.FailedPrecondition,
+			fmt.Sprintf("tenant with id %s not found", req.Rule.TenantId), "TenantNotFound", map[string]string{"tenantId": req.Rule.TenantId})
+	}
+	if err != nil {
+		return nil, h.routingRuleError("CreateRoutingRule", "", err)
+	}
+	return &pb.CreateRoutingRuleResponse{Rule: rule}, nil
+}
+
+func (h *ConnectorsGrpcHandler) GetRoutingRule(ctx context.Context, req *pb.GetRoutingRuleRequest) (*pb.GetRoutingRuleResponse, error) {
+	if req.RuleId == "" {
+		return nil, h.invalidArgument("GetRoutingRule", []*errdetails.BadRequest_FieldViolation{{
+			Field:       "ruleId",
+			Description: "missing required field: ruleId",
+		}})
+	}
+
+	rule, err := h.connectorService.GetRoutingRule(ctx, req.RuleId)
+	if err != nil {
+		return nil, h.routingRuleError("GetRoutingRule", req.RuleId, err)
+	}
+	return &pb.GetRoutingRuleResponse{Rule: rule}, nil
+}
+
+func (h *ConnectorsGrpcHandler) ListRoutingRules(ctx context.Context, req *pb.ListRoutingRulesRequest) (*pb.ListRoutingRulesResponse, error) {
+	if req.TenantId == "" {
+		return nil, h.invalidArgument("ListRoutingRules", []*errdetails.BadRequest_FieldViolation{{
+			Field:       "tenantId",
+			Description: "missing required field: tenantId",
+		}})
+	}
+
+	rules, err := h.connectorService.ListRoutingRules(ctx, req.TenantId)
+	if err != nil {
+		return nil, h.routingRuleError("ListRoutingRules", "", err)
+	}
+	return &pb.ListRoutingRulesResponse{Rules: rules}, nil
+}
+
+func (h *ConnectorsGrpcHandler) UpdateRoutingRule(ctx context.Context, req *pb.UpdateRoutingRuleRequest) (*pb.UpdateRoutingRuleResponse, error) {
+	violations := validateRoutingRule(req.Rule)
+	if req.Rule.GetId() == "" {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "rule.id",
+			Description: "missing required field: rule.id",
+		})
+	}
+	if len(violations) > 0 {
+		return nil, h.invalidArgument("UpdateRoutingRule", violations)
+	}
+
+	rule, err := h.connectorService.UpdateRoutingRule(ctx, req.Rule)
+	if err != nil {
+		return nil, h.routingRuleError("UpdateRoutingRule", req.Rule.Id, err)
+	}
+	return &pb.UpdateRoutingRuleResponse{Rule: rule}, nil
+}
+
+func (h *ConnectorsGrpcHandler) DeleteRoutingRule(ctx context.Context, req *pb.DeleteRoutingRuleRequest) (*pb.DeleteRoutingRuleResponse, error) {
+	if req.RuleId == "" {
+		return nil, h.invalidArgument("DeleteRoutingRule", []*errdetails.BadRequest_FieldViolation{{
+			Field:       "ruleId",
+			Description: "missing required field: ruleId",
+		}})
+	}
+
+	if err := h.connectorService.DeleteRoutingRule(ctx, req.RuleId); err != nil {
+		return nil, h.routingRuleError("DeleteRoutingRule", req.RuleId, err)
+	}
+	return &pb.DeleteRoutingRuleResponse{}, nil
+}
+
+func (h *ConnectorsGrpcHandler) RouteMessage(ctx context.Context, req *pb.RouteMessageRequest) (*pb.RouteMessageResponse, error) {
+	var violations []*errdetails.BadRequest_FieldViolation
+	if req.TenantId == "" {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "tenantId",
+			Description: "missing required field: tenantId",
+		})
+	}
+	if req.Message == "" {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "message",
+			Description: "missing required field: message",
+		})
+	}
+	if len(violations) > 0 {
+		return nil, h.invalidArgument("RouteMessage", violations)
+	}
+
+	results, err := h.connectorService.RouteMessage(ctx, req.TenantId, req.Labels, req.Message)
+	if err != nil {
+		h.logger.Error("RouteMessage internal error", "tenant", req.TenantId, "err", err)
+		return nil, h.errorWithInfo("RouteMessage", codes.Internal, "internal server error: failed to route message",
+			"InternalError", map[string]string{"tenantId": req.TenantId})
+	}
+	return &pb.RouteMessageResponse{Results: results}, nil
+}
+
+// routingRuleError maps service errors of the routing rule RPCs to gRPC statuses.
+func (h *ConnectorsGrpcHandler) routingRuleError(method, ruleID string, err error) error {
+	switch {
+	case errors.Is(err, errs.ErrRoutingRuleNotFound):
+		h.logger.Warn(method+" not found", "id", ruleID)
+		return h.errorWithInfo(method, codes.NotFound, fmt.Sprintf("routing rule with id %s not found", ruleID),
+			"RoutingRuleNotFound", map[string]string{"ruleId": ruleID})
+	case errors.Is(err, errs.ErrInvalidRouteTarget):
+		h.logger.Warn(method+" invalid target", "id", ruleID, "err", err)
+		return h.errorWithInfo(method, codes.FailedPrecondition, err.Error(),
+			"InvalidRouteTarget", map[string]string{"ruleId": ruleID})
+	default:
+		h.logger.Error(method+" internal error", "id", ruleID, "err", err)
+		return h.errorWithInfo(method, codes.Internal, "internal server error: failed to process routing rule",
+			"InternalError", map[string]string{"ruleId": ruleID})
+	}
+}
+
+// validateRoutingRule reports the field violations shared by create and update.
+func validateRoutingRule(rule *pb.RoutingRule) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
+	if rule == nil {
+		return append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "rule",
+			Description: "missing required field: rule",
+		})
+	}
+	if rule.Name == "" {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "rule.name",
+			Description: "rule name is required",
+		})
+	}
+	if len(rule.Targets) == 0 {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "rule.targets",
+			Description: "at least one target is required",
+		})
+	}
+	for i, t := range rule.Targets {
+		if t.GetConnectorId() == "" {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       fmt.Sprintf("rule.targets[%d].connectorId", i),
+				Description: "connector id is required",
+			})
+		}
+	}
+	return violations
+}
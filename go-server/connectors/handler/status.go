@@ -0,0 +1,35 @@
+package handler
+
+import (
+	errdetails "google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// invalidArgument returns an InvalidArgument status carrying the field violations as BadRequest details.
+func (h *ConnectorsGrpcHandler) invalidArgument(method string, violations []*errdetails.BadRequest_FieldViolation) error {
+	br := &errdetails.BadRequest{FieldViolations: violations}
+	st := status.New(codes.InvalidArgument, "invalid input parameters")
+	stWithDetails, err := st.WithDetails(br)
+	if err != nil {
+		h.logger.Error(method+": failed to attach bad request details", "error", err)
+		return st.Err()
+	}
+	return stWithDetails.Err()
+}
+
+// errorWithInfo returns a status with the given code and message carrying an ErrorInfo detail.
+func (h *ConnectorsGrpcHandler) errorWithInfo(method string, code codes.Code, msg, reason string, metadata map[string]string) error {
+	info := &errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   "connectors.service",
+		Metadata: metadata,
+	}
+	st := status.New(code, msg)
+	stWithDetails, err := st.WithDetails(info)
+	if err != nil {
+		h.logger.Error(method+": failed to attach error details", "error", err)
+		return st.Err()
+	}
+	return stWithDetails.Err()
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"connector-recruitment/go-server/connectors/errs"
+	pb "connector-recruitment/go-server/connectors/genproto"
+
+	errdetails "google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+)
+
+// maxTenantIDLength matches the tenants.id column.
+const maxTenantIDLength = 255
+
+func (h *ConnectorsGrpcHandler) CreateTenant(ctx context.Context, req *pb.CreateTenantRequest) (*pb.CreateTenantResponse, error) {
+	violations := validateTenant(req.Tenant)
+	if req.Tenant != nil && len(req.Tenant.Id) > maxTenantIDLength {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "tenant.id",
+			Description: fmt.Sprintf("tenant id must be at most %d characters", maxTenantIDLength),
+		})
+	}
+	if len(violations) > 0 {
+		return nil, h.invalidArgument("CreateTenant", violations)
+	}
+
+	tenant, err := h.connectorService.CreateTenant(ctx, req.Tenant)
+	if err != nil {
+		return nil, h.tenantError("CreateTenant", req.Tenant.Id, err)
+	}
+	return &pb.CreateTenantResponse{Tenant: tenant}, nil
+}
+
+func (h *ConnectorsGrpcHandler) GetTenant(ctx context.Context, req *pb.GetTenantRequest) (*pb.GetTenantResponse, error) {
+	if req.TenantId == "" {
+		return nil, h.invalidArgument("GetTenant", []*errdetails.BadRequest_FieldViolation{{
+			Field:       "tenantId",
+			Description: "missing required field: tenantId",
+		}})
+	}
+
+	tenant, err := h.connectorService.GetTenant(ctx, req.TenantId)
+	if err != nil {
+		return nil, h.tenantError("GetTenant", req.TenantId, err)
+	}
+	return &pb.GetTenantResponse{Tenant: tenant}, nil
+}
+
+func (h *ConnectorsGrpcHandler) ListTenants(ctx context.Context, req *pb.ListTenantsRequest) (*pb.ListTenantsResponse, error) {
+	tenants, err := h.connectorService.ListTenants(ctx)
+	if err != nil {
+		return nil, h.tenantError("ListTenants", "", err)
+	}
+	return &pb.ListTenantsResponse{Tenants: tenants}, nil
+}
+
+func (h *ConnectorsGrpcHandler) UpdateTenant(ctx context.Context, req *pb.UpdateTenantRequest) (*pb.UpdateTenantResponse, error) {
+	if violations := validateTenantUpdate(req.Tenant, req.UpdateMask.GetPaths()); len(violations) > 0 {
+		return nil, h.invalidArgument("UpdateTenant", violations)
+	}
+
+	tenant, err := h.connectorService.UpdateTenant(ctx, req.Tenant, req.UpdateMask)
+	if err != nil {
+		return nil, h.tenantError("UpdateTenant", req.Tenant.Id, err)
+	}
+	return &pb.UpdateTenantResponse{Tenant: tenant}, nil
+}
+
+// tenantError maps service errors of the tenant RPCs to gRPC statuses.
+func (h *ConnectorsGrpcHandler) tenantError(method, tenantID string, err error) error {
+	metadata := map[string]string{"tenantId": tenantID}
+	switch {
+	case errors.Is(err, errs.ErrTenantNotFound):
+		h.logger.Warn(method+" tenant not found", "id", tenantID)
+		return h.errorWithInfo(method, codes.NotFound, fmt.Sprintf("tenant with id %s not found", tenantID),
+			"TenantNotFound", metadata)
+	case errors.Is(err, errs.ErrTenantExistAlready):
+		return h.errorWithInfo(method, codes.AlreadyExists, fmt.Sprintf("tenant with id %s exist already", tenantID),
+			"TenantAlreadyExists", metadata)
+	default:
+		h.logger.Error(method+" internal error", "id", tenantID, "err", err)
+		return h.errorWithInfo(method, codes.Internal, "internal server error: failed to process tenant",
+			"InternalError", metadata)
+	}
+}
+
+// tenantUpdatePaths are the update_mask paths of UpdateTenant.
+var tenantUpdatePaths = []string{"name", "status", "settings"}
+
+// validateTenantUpdate reports the field violations of an update of the fields listed in
+// paths, all of them when paths is empty. A status left unspecified would reactivate a
+// suspended tenant, so it is rejected rather than defaulted.
+func validateTenantUpdate(tenant *pb.Tenant, paths []string) []*errdetails.BadRequest_FieldViolation {
+	if len(paths) == 0 {
+		paths = tenantUpdatePaths
+	}
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, path := range paths {
+		if !slices.Contains(tenantUpdatePaths, path) {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       "updateMask",
+				Description: fmt.Sprintf("unknown field %q, want one of %s", path, strings.Join(tenantUpdatePaths, ", ")),
+			})
+		}
+	}
+	if tenant == nil {
+		return append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "tenant",
+			Description: "missing required field: tenant",
+		})
+	}
+	if tenant.Id == "" {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "tenant.id",
+			Description: "missing required field: tenant.id",
+		})
+	}
+	if slices.Contains(paths, "name") && tenant.Name == "" {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "tenant.name",
+			Description: "tenant name is required",
+		})
+	}
+	if slices.Contains(paths, "status") && tenant.Status == pb.TenantStatus_TENANT_STATUS_UNSPECIFIED {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "tenant.status",
+			Description: "tenant status is required when updated",
+		})
+	}
+	return violations
+}
+
+// validateTenant reports the field violations of a tenant to create.
+func validateTenant(tenant *pb.Tenant) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
+	if tenant == nil {
+		return append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "tenant",
+			Description: "missing required field: tenant",
+		})
+	}
+	if tenant.Id == "" {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "tenant.id",
+			Description: "missing required field: tenant.id",
+		})
+	}
+	if tenant.Name == "" {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "tenant.name",
+			Description: "tenant name is required",
+		})
+	}
+	return violations
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"connector-recruitment/go-server/connectors/errs"
+	pb "connector-recruitment/go-server/connectors/genproto"
+
+	errdetails "google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+)
+
+func (h *ConnectorsGrpcHandler) RotateConnectorToken(ctx context.Context, req *pb.RotateConnectorTokenRequest) (*pb.RotateConnectorTokenResponse, error) {
+	var violations []*errdetails.BadRequest_FieldViolation
+	if req.ConnectorId == "" {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "connectorId",
+			Description: "missing required field: connectorId",
+		})
+	}
+	if req.Token == "" {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "token",
+			Description: "missing required field: token",
+		})
+	}
+	if len(violations) > 0 {
+		return nil, h.invalidArgument("RotateConnectorToken", violations)
+	}
+
+	connector, err := h.connectorService.RotateConnectorToken(ctx, req.ConnectorId, req.Token, req.Etag, req.Validate)
+	if err != nil {
+		return nil, h.tokenError("RotateConnectorToken", req.ConnectorId, err)
+	}
+	return &pb.RotateConnectorTokenResponse{Connector: connector}, nil
+}
+
+func (h *ConnectorsGrpcHandler) RollbackConnectorToken(ctx context.Context, req *pb.RollbackConnectorTokenRequest) (*pb.RollbackConnectorTokenResponse, error) {
+	if req.ConnectorId == "" {
+		return nil, h.invalidArgument("RollbackConnectorToken", []*errdetails.BadRequest_FieldViolation{{
+			Field:       "connectorId",
+			Description: "missing required field: connectorId",
+		}})
+	}
+
+	connector, err := h.connectorService.RollbackConnectorToken(ctx, req.ConnectorId, req.Etag)
+	if err != nil {
+		return nil, h.tokenError("RollbackConnectorToken", req.ConnectorId, err)
+	}
+	return &pb.RollbackConnectorTokenResponse{Connector: connector}, nil
+}
+
+// tokenError maps service errors of the token RPCs to gRPC statuses.
+func (h *ConnectorsGrpcHandler) tokenError(method, connectorID string, err error) error {
+	if st, ok := h.etagError(method, connectorID, err); ok {
+		return st
+	}
+	metadata := map[string]string{"connectorId": connectorID}
+	switch {
+	case errors.Is(err, errs.ErrConnectorNotFound):
+		h.logger.Warn(method+" connector not found", "id", connectorID)
+		return h.errorWithInfo(method, codes.NotFound, fmt.Sprintf("connector with id %s not found", connectorID),
+			"ConnectorNotFound", metadata)
+	case errors.Is(err, errs.ErrTokenValidationFailed):
+		h.logger.Warn(method+" token rejected", "id", connectorID, "err", err)
+		return h.errorWithInfo(method, codes.FailedPrecondition, err.Error(), "TokenValidationFailed", metadata)
+	case errors.Is(err, errs.ErrNoPreviousSecretVersion):
+		return h.errorWithInfo(method, codes.FailedPrecondition, "connector token was never rotated",
+			"NoPreviousToken", metadata)
+	case errors.Is(err, errs.ErrNoSecret):
+		return h.errorWithInfo(method, codes.FailedPrecondition, "connector has no token",
+			"NoToken", metadata)
+	default:
+		h.logger.Error(method+" internal error", "id", connectorID, "err", err)
+		return h.errorWithInfo(method, codes.Internal, "internal server error: failed to update connector token",
+			"InternalError", metadata)
+	}
+}
@@ -0,0 +1,217 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"connector-recruitment/go-server/connectors/logger"
+)
+
+const DefaultTimeout = 10 * time.Second
+
+// ErrStartTLSUnsupported is returned when the server does not offer STARTTLS.
+// Credentials are never sent over an unencrypted connection.
+var ErrStartTLSUnsupported = errors.New("smtp server does not support STARTTLS")
+
+type Client struct {
+	tlsConfig *tls.Config
+	timeout   time.Duration
+	logger    logger.Logger
+}
+
+// NewClient creates an SMTP client. A nil tlsConfig uses the system roots and the
+// server's host name; tests pass one that trusts their in-process server.
+func NewClient(tlsConfig *tls.Config, timeout time.Duration, logger logger.Logger) *Client {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Client{
+		tlsConfig: tlsConfig,
+		timeout:   timeout,
+		logger:    logger,
+	}
+}
+
+// Message is a single email sent to every recipient in To.
+type Message struct {
+	From    string
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Server holds the address and credentials of the SMTP relay.
+type Server struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+// Send delivers msg through the server using STARTTLS and, when a username is set, AUTH PLAIN.
+func (c *Client) Send(ctx context.Context, srv Server, msg Message) error {
+	if len(msg.To) == 0 {
+		return errors.New("email message has no recipients")
+	}
+	for _, h := range append([]string{msg.From, msg.Subject}, msg.To...) {
+		if strings.ContainsAny(h, "\r\n") {
+			return errors.New("email headers must not contain line breaks")
+		}
+	}
+
+	addr := net.JoinHostPort(srv.Host, strconv.Itoa(srv.Port))
+	dialer := &net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	deadline := time.Now().Add(c.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, srv.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to create smtp client: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); !ok {
+		return ErrStartTLSUnsupported
+	}
+	tlsConfig := c.tlsConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{ServerName: srv.Host}
+	}
+	if err := client.StartTLS(tlsConfig); err != nil {
+		return fmt.Errorf("failed to start tls: %w", err)
+	}
+
+	if srv.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", srv.Username, srv.Password, srv.Host)); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+
+	if err := client.Mail(envelopeAddress(msg.From)); err != nil {
+		return fmt.Errorf("failed to set sender: %w", err)
+	}
+	for _, rcpt := range msg.To {
+		if err := client.Rcpt(envelopeAddress(rcpt)); err != nil {
+			return fmt.Errorf("failed to add recipient %s: %w", rcpt, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start data: %w", err)
+	}
+	body, err := render(msg)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	if err := client.Quit(); err != nil {
+		c.logger.Warn("smtp QUIT failed", "err", err)
+	}
+
+	c.logger.Info("Email sent successfully", "from", msg.From, "recipients", len(msg.To))
+	return nil
+}
+
+// envelopeAddress strips the display name from addresses like "Alerts <alerts@example.com>".
+func envelopeAddress(addr string) string {
+	if parsed, err := mail.ParseAddress(addr); err == nil {
+		return parsed.Address
+	}
+	return addr
+}
+
+// render builds a multipart/alternative MIME message with plain-text and HTML parts.
+func render(msg Message) ([]byte, error) {
+	boundary, err := newBoundary()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", msg.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+
+	if err := writePart(&buf, boundary, "text/plain", msg.Body); err != nil {
+		return nil, err
+	}
+	if err := writePart(&buf, boundary, "text/html", renderHTML(msg.Body)); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+
+	return buf.Bytes(), nil
+}
+
+func writePart(buf *bytes.Buffer, boundary, contentType, content string) error {
+	fmt.Fprintf(buf, "--%s\r\n", boundary)
+	fmt.Fprintf(buf, "Content-Type: %s; charset=UTF-8\r\n", contentType)
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	qp := quotedprintable.NewWriter(buf)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return fmt.Errorf("failed to encode %s part: %w", contentType, err)
+	}
+	if err := qp.Close(); err != nil {
+		return fmt.Errorf("failed to encode %s part: %w", contentType, err)
+	}
+	buf.WriteString("\r\n")
+	return nil
+}
+
+// renderHTML escapes the plain-text body and keeps its paragraphs and line breaks.
+func renderHTML(body string) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html><html><body>")
+	for _, para := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n\n") {
+		lines := strings.Split(para, "\n")
+		for i, line := range lines {
+			lines[i] = html.EscapeString(line)
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.Join(lines, "<br>"))
+		b.WriteString("</p>")
+	}
+	b.WriteString("</body></html>")
+	return b.String()
+}
+
+func newBoundary() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate mime boundary: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
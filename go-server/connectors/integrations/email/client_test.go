@@ -0,0 +1,263 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"connector-recruitment/go-server/connectors/integrations/email/emailtest"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func newServer(t *testing.T, username, password string) *emailtest.Server {
+	t.Helper()
+	srv, err := emailtest.NewServer(username, password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { srv.Close() })
+	return srv
+}
+
+func TestSend(t *testing.T) {
+	srv := newServer(t, "", "")
+	client := NewClient(srv.ClientTLSConfig, time.Second, discard)
+
+	msg := Message{
+		From:    "Alerts <alerts@example.com>",
+		To:      []string{"a@example.com", "B <b@example.com>"},
+		Subject: "Déploiement terminé",
+		Body:    "first line\nsecond <line>\n\nnext paragraph",
+	}
+	if err := client.Send(context.Background(), Server{Host: srv.Host, Port: srv.Port}, msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	got := srv.Messages()
+	if len(got) != 1 {
+		t.Fatalf("the server accepted %d messages, want 1", len(got))
+	}
+	if got[0].From != "alerts@example.com" {
+		t.Errorf("envelope sender = %q, want %q", got[0].From, "alerts@example.com")
+	}
+	if want := []string{"a@example.com", "b@example.com"}; fmt.Sprint(got[0].To) != fmt.Sprint(want) {
+		t.Errorf("envelope recipients = %q, want %q", got[0].To, want)
+	}
+	if got[0].Username != "" {
+		t.Errorf("the client authenticated as %q without a username", got[0].Username)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(got[0].Data))
+	if err != nil {
+		t.Fatalf("failed to parse the message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != msg.Subject {
+		t.Errorf("Subject = %q, %v, want %q", subject, err, msg.Subject)
+	}
+	if to := parsed.Header.Get("To"); to != "a@example.com, B <b@example.com>" {
+		t.Errorf("To = %q", to)
+	}
+
+	_, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("Content-Type: %v", err)
+	}
+	parts := multipart.NewReader(parsed.Body, params["boundary"])
+	want := []struct{ contentType, body string }{
+		{"text/plain", "first line\r\nsecond <line>\r\n\r\nnext paragraph"},
+		{"text/html", "<!DOCTYPE html><html><body><p>first line<br>second &lt;line&gt;</p><p>next paragraph</p></body></html>"},
+	}
+	for _, w := range want {
+		part, err := parts.NextPart()
+		if err != nil {
+			t.Fatalf("NextPart: %v", err)
+		}
+		if mediaType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type")); mediaType != w.contentType {
+			t.Errorf("part of type %s, want %s", mediaType, w.contentType)
+		}
+		content, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("reading the %s part: %v", w.contentType, err)
+		}
+		if got := strings.TrimRight(string(content), "\r\n"); got != w.body {
+			t.Errorf("%s part = %q, want %q", w.contentType, got, w.body)
+		}
+	}
+}
+
+func TestSendAuth(t *testing.T) {
+	srv := newServer(t, "user", "secret")
+	client := NewClient(srv.ClientTLSConfig, time.Second, discard)
+	msg := Message{From: "alerts@example.com", To: []string{"a@example.com"}, Subject: "s", Body: "b"}
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantErr  string
+	}{
+		{"valid credentials", "user", "secret", ""},
+		{"wrong password", "user", "wrong", "failed to authenticate"},
+		{"no username", "", "", "failed to set sender"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(srv.Messages())
+			err := client.Send(context.Background(), Server{Host: srv.Host, Port: srv.Port, Username: tt.username, Password: tt.password}, msg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Send: %v", err)
+				}
+				messages := srv.Messages()
+				if len(messages) != before+1 || messages[before].Username != tt.username {
+					t.Errorf("the server did not accept a message from %q", tt.username)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Send: got %v, want an error containing %q", err, tt.wantErr)
+			}
+			if len(srv.Messages()) != before {
+				t.Errorf("the server accepted a message despite %v", err)
+			}
+		})
+	}
+}
+
+func TestSendFailures(t *testing.T) {
+	srv := newServer(t, "", "")
+	valid := Message{From: "alerts@example.com", To: []string{"a@example.com"}, Subject: "s", Body: "b"}
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	closed.Close()
+
+	tests := []struct {
+		name      string
+		tlsConfig bool
+		server    Server
+		msg       Message
+		wantErr   string
+	}{
+		{
+			name:      "no recipients",
+			tlsConfig: true,
+			server:    Server{Host: srv.Host, Port: srv.Port},
+			msg:       Message{From: "alerts@example.com", Subject: "s", Body: "b"},
+			wantErr:   "has no recipients",
+		},
+		{
+			name:      "line break in subject",
+			tlsConfig: true,
+			server:    Server{Host: srv.Host, Port: srv.Port},
+			msg:       Message{From: "alerts@example.com", To: []string{"a@example.com"}, Subject: "s\r\nBcc: x@example.com"},
+			wantErr:   "must not contain line breaks",
+		},
+		{
+			name:      "line break in recipient",
+			tlsConfig: true,
+			server:    Server{Host: srv.Host, Port: srv.Port},
+			msg:       Message{From: "alerts@example.com", To: []string{"a@example.com\nBcc: x@example.com"}},
+			wantErr:   "must not contain line breaks",
+		},
+		{
+			name:    "untrusted certificate",
+			server:  Server{Host: srv.Host, Port: srv.Port},
+			msg:     valid,
+			wantErr: "failed to start tls",
+		},
+		{
+			name:      "connection refused",
+			tlsConfig: true,
+			server:    Server{Host: "127.0.0.1", Port: closedPort},
+			msg:       valid,
+			wantErr:   "failed to connect",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(nil, time.Second, discard)
+			if tt.tlsConfig {
+				client = NewClient(srv.ClientTLSConfig, time.Second, discard)
+			}
+			err := client.Send(context.Background(), tt.server, tt.msg)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Send: got %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+	if n := len(srv.Messages()); n != 0 {
+		t.Errorf("the server accepted %d messages, want none", n)
+	}
+}
+
+func TestSendWithoutStartTLS(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	commands := make(chan string, 10)
+	go servePlainSMTP(lis, commands)
+
+	addr := lis.Addr().(*net.TCPAddr)
+	client := NewClient(nil, time.Second, discard)
+	srv := Server{Host: "127.0.0.1", Port: addr.Port, Username: "user", Password: "secret"}
+	msg := Message{From: "alerts@example.com", To: []string{"a@example.com"}, Subject: "s", Body: "b"}
+	if err := client.Send(context.Background(), srv, msg); !errors.Is(err, ErrStartTLSUnsupported) {
+		t.Fatalf("Send: got %v, want %v", err, ErrStartTLSUnsupported)
+	}
+	lis.Close()
+	for cmd := range commands {
+		if strings.HasPrefix(cmd, "AUTH") || strings.HasPrefix(cmd, "MAIL") {
+			t.Errorf("the client sent %q over a plain connection", cmd)
+		}
+	}
+}
+
+// servePlainSMTP answers a single session like a server without STARTTLS, sending the
+// commands it receives to commands, which it closes at the end of the session.
+func servePlainSMTP(lis net.Listener, commands chan<- string) {
+	defer close(commands)
+	conn, err := lis.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	r := bufio.NewReader(conn)
+	fmt.Fprintf(conn, "220 plain ESMTP\r\n")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		commands <- line
+		switch verb, _, _ := strings.Cut(line, " "); strings.ToUpper(verb) {
+		case "EHLO":
+			fmt.Fprintf(conn, "250-plain\r\n250 AUTH PLAIN\r\n")
+		case "QUIT":
+			fmt.Fprintf(conn, "221 bye\r\n")
+			return
+		default:
+			fmt.Fprintf(conn, "250 ok\r\n")
+		}
+	}
+}
@@ -0,0 +1,242 @@
+// Package emailtest provides an in-process SMTP server for exercising the email
+// connector without a real relay, in the spirit of net/http/httptest.
+package emailtest
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message is an email accepted by the server.
+type Message struct {
+	From     string
+	To       []string
+	Data     string
+	Username string
+}
+
+// Server is a minimal SMTP server supporting EHLO, STARTTLS, AUTH PLAIN, MAIL, RCPT and DATA.
+type Server struct {
+	// Host and Port are where the server listens.
+	Host string
+	Port int
+	// ClientTLSConfig trusts the server's self-signed certificate.
+	ClientTLSConfig *tls.Config
+
+	listener  net.Listener
+	tlsConfig *tls.Config
+	username  string
+	password  string
+
+	mu       sync.Mutex
+	messages []Message
+	wg       sync.WaitGroup
+}
+
+// NewServer starts a server on a loopback port that accepts the given credentials.
+// Callers must Close it when done.
+func NewServer(username, password string) (*Server, error) {
+	cert, err := selfSignedCert()
+	if err != nil {
+		return nil, err
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert.Leaf)
+
+	addr := lis.Addr().(*net.TCPAddr)
+	s := &Server{
+		Host:            addr.IP.String(),
+		Port:            addr.Port,
+		ClientTLSConfig: &tls.Config{RootCAs: pool, ServerName: addr.IP.String()},
+		listener:        lis,
+		tlsConfig:       &tls.Config{Certificates: []tls.Certificate{cert}},
+		username:        username,
+		password:        password,
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Messages returns the messages accepted so far.
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// Close stops the server and waits for open sessions to finish.
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+
+	var (
+		secure bool
+		user   string
+		msg    Message
+	)
+	reply("220 emailtest ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			if secure {
+				fmt.Fprintf(conn, "250-emailtest\r\n250 AUTH PLAIN\r\n")
+			} else {
+				fmt.Fprintf(conn, "250-emailtest\r\n250 STARTTLS\r\n")
+			}
+		case "STARTTLS":
+			reply("220 ready to start TLS")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, r, secure = tlsConn, bufio.NewReader(tlsConn), true
+		case "AUTH":
+			if !secure {
+				reply("530 must issue STARTTLS first")
+				continue
+			}
+			mech, initial, _ := strings.Cut(arg, " ")
+			if !strings.EqualFold(mech, "PLAIN") {
+				reply("504 unrecognized authentication type")
+				continue
+			}
+			decoded, err := base64.StdEncoding.DecodeString(initial)
+			parts := strings.Split(string(decoded), "\x00")
+			if err != nil || len(parts) != 3 || parts[1] != s.username || parts[2] != s.password {
+				reply("535 authentication failed")
+				continue
+			}
+			user = parts[1]
+			reply("235 authentication succeeded")
+		case "MAIL":
+			if s.username != "" && user == "" {
+				reply("530 authentication required")
+				continue
+			}
+			msg = Message{From: trimPath(arg), Username: user}
+			reply("250 ok")
+		case "RCPT":
+			msg.To = append(msg.To, trimPath(arg))
+			reply("250 ok")
+		case "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			msg.Data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			msg = Message{}
+			reply("250 ok: queued")
+		case "RSET", "NOOP":
+			reply("250 ok")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 command not implemented")
+		}
+	}
+}
+
+// trimPath turns "FROM:<a@b>" or "TO:<a@b>" style arguments into "a@b".
+func trimPath(arg string) string {
+	if _, rest, ok := strings.Cut(arg, ":"); ok {
+		arg = rest
+	}
+	arg = strings.TrimSpace(arg)
+	if i := strings.Index(arg, ">"); i >= 0 {
+		arg = arg[:i]
+	}
+	return strings.TrimPrefix(arg, "<")
+}
+
+func selfSignedCert() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to generate key: %w", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "emailtest"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		DNSNames:     []string{"localhost"},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to create certificate: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, nil
+}
+
+// Addr returns the host:port the server listens on.
+func (s *Server) Addr() string {
+	return net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+}
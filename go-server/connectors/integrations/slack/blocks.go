@@ -0,0 +1,42 @@
+package slack
+
+// Block is a Block Kit layout block. Only the block types the service produces are modelled.
+type Block struct {
+	Type string     `json:"type"`
+	Text *TextBlock `json:"text,omitempty"`
+}
+
+// TextBlock is a Block Kit text object.
+type TextBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+const (
+	BlockTypeSection = "section"
+	BlockTypeHeader  = "header"
+	BlockTypeDivider = "divider"
+
+	TextTypeMrkdwn    = "mrkdwn"
+	TextTypePlainText = "plain_text"
+
+	// MaxSectionTextLength is the longest text Slack accepts in a section block.
+	MaxSectionTextLength = 3000
+	// MaxHeaderTextLength is the longest text Slack accepts in a header block.
+	MaxHeaderTextLength = 150
+)
+
+// SectionBlock returns a section block rendering text as mrkdwn.
+func SectionBlock(text string) Block {
+	return Block{Type: BlockTypeSection, Text: &TextBlock{Type: TextTypeMrkdwn, Text: text}}
+}
+
+// HeaderBlock returns a header block with plain text.
+func HeaderBlock(text string) Block {
+	return Block{Type: BlockTypeHeader, Text: &TextBlock{Type: TextTypePlainText, Text: text}}
+}
+
+// DividerBlock returns a divider block.
+func DividerBlock() Block {
+	return Block{Type: BlockTypeDivider}
+}
@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"connector-recruitment/go-server/connectors/logger"
 )
@@ -50,43 +52,149 @@ type SlackResponse struct {
 	} `json:"message,omitempty"`
 }
 
-func (c *Client) SendMessageToChannel(ctx context.Context, token, channelID, msg string) error {
-	payload := map[string]string{
-		"channel": channelID,
-		"text":    msg,
+// Message is the payload of chat.postMessage.
+type Message struct {
+	Channel string  `json:"channel"`
+	Text    string  `json:"text"`
+	Blocks  []Block `json:"blocks,omitempty"`
+	// Mrkdwn set to false makes Slack render Text literally.
+	Mrkdwn *bool `json:"mrkdwn,omitempty"`
+	// ThreadTS posts the message as a reply in the thread of that message.
+	ThreadTS string `json:"thread_ts,omitempty"`
+}
+
+func (c *Client) SendMessageToChannel(ctx context.Context, token, channelID, msg string) (*SlackResponse, error) {
+	return c.PostMessage(ctx, token, Message{Channel: channelID, Text: msg})
+}
+
+// PostSplitMessage posts msg, split with SplitMessage when it exceeds Slack's limits.
+// The first part is posted to the channel and the others as replies in its thread. The
+// responses of the parts posted so far are returned even when a later part fails.
+func (c *Client) PostSplitMessage(ctx context.Context, token string, msg Message) ([]*SlackResponse, error) {
+	parts := SplitMessage(msg)
+	responses := make([]*SlackResponse, 0, len(parts))
+	for i, part := range parts {
+		if i > 0 {
+			part.ThreadTS = responses[0].TS
+		}
+		resp, err := c.PostMessage(ctx, token, part)
+		if err != nil {
+			return responses, fmt.Errorf("failed to post part %d of %d: %w", i+1, len(parts), err)
+		}
+		responses = append(responses, resp)
+	}
+
+	if len(parts) > 1 {
+		c.logger.Info("Oversized message split into thread", "slack-channel", msg.Channel, "parts", len(parts))
 	}
+	return responses, nil
+}
 
-	jsonData, err := json.Marshal(payload)
+// PostMessage posts msg with chat.postMessage. When Blocks are set, Text is the
+// notification fallback.
+func (c *Client) PostMessage(ctx context.Context, token string, msg Message) (*SlackResponse, error) {
+	jsonData, err := json.Marshal(msg)
 	if err != nil {
-		return fmt.Errorf("failed to marshal JSON: %w", err)
+		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/chat.postMessage", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// headers
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
 	req.Header.Set("Authorization", "Bearer "+token) // Add Authorization token
 
 	// executes the request
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	var slackResp SlackResponse
 	if err := json.NewDecoder(resp.Body).Decode(&slackResp); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
+		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	// handle slack response
 	if !slackResp.Ok {
-		return fmt.Errorf("slack API error: %s", slackResp.Error)
+		return nil, &Error{Method: "chat.postMessage", Code: slackResp.Error}
 	}
 
 	c.logger.Info("Message sent successfully", "slack-channel", slackResp.Channel, "timestamp", slackResp.TS)
+	return &slackResp, nil
+}
+
+// AuthTestResponse is the response of auth.test.
+type AuthTestResponse struct {
+	Ok     bool   `json:"ok"`
+	Error  string `json:"error,omitempty"`
+	URL    string `json:"url,omitempty"`
+	Team   string `json:"team,omitempty"`
+	TeamID string `json:"team_id,omitempty"`
+	UserID string `json:"user_id,omitempty"`
+	BotID  string `json:"bot_id,omitempty"`
+}
+
+// AuthTest checks that the token is valid.
+func (c *Client) AuthTest(ctx context.Context, token string) (*AuthTestResponse, error) {
+	var resp AuthTestResponse
+	if err := c.call(ctx, token, "auth.test", nil, &resp); err != nil {
+		return nil, err
+	}
+	if !resp.Ok {
+		return nil, &Error{Method: "auth.test", Code: resp.Error}
+	}
+	return &resp, nil
+}
+
+// Conversation is the subset of a conversation object returned by conversations.info.
+type Conversation struct {
+	ID         string `json:"id"`
+	Name       string `json:"name,omitempty"`
+	IsArchived bool   `json:"is_archived,omitempty"`
+	IsMember   bool   `json:"is_member,omitempty"`
+}
+
+type conversationInfoResponse struct {
+	Ok      bool         `json:"ok"`
+	Error   string       `json:"error,omitempty"`
+	Channel Conversation `json:"channel"`
+}
+
+// ConversationInfo returns the channel as seen by the token's user, including whether it
+// is a member of it.
+func (c *Client) ConversationInfo(ctx context.Context, token, channelID string) (*Conversation, error) {
+	var resp conversationInfoResponse
+	if err := c.call(ctx, token, "conversations.info", url.Values{"channel": {channelID}}, &resp); err != nil {
+		return nil, err
+	}
+	if !resp.Ok {
+		return nil, &Error{Method: "conversations.info", Code: resp.Error}
+	}
+	return &resp.Channel, nil
+}
+
+// call posts form encoded params to a Web API method and decodes the response into out.
+func (c *Client) call(ctx context.Context, token, method string, params url.Values, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/"+method, strings.NewReader(params.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
 	return nil
 }
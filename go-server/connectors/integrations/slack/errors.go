@@ -0,0 +1,60 @@
+package slack
+
+import "errors"
+
+// Error codes returned by the Slack Web API that cannot succeed on retry: the token is no
+// longer valid or the bot can no longer post to the channel.
+const (
+	ErrCodeInvalidAuth     = "invalid_auth"
+	ErrCodeTokenRevoked    = "token_revoked"
+	ErrCodeAccountInactive = "account_inactive"
+	ErrCodeChannelNotFound = "channel_not_found"
+)
+
+// ErrCodeMissingScope is returned when the token lacks the OAuth scope a method needs.
+const ErrCodeMissingScope = "missing_scope"
+
+var (
+	ErrInvalidAuth     = &Error{Code: ErrCodeInvalidAuth}
+	ErrTokenRevoked    = &Error{Code: ErrCodeTokenRevoked}
+	ErrAccountInactive = &Error{Code: ErrCodeAccountInactive}
+	ErrChannelNotFound = &Error{Code: ErrCodeChannelNotFound}
+)
+
+// Error is the error reported by a Slack Web API response with "ok": false.
+type Error struct {
+	// Method is the Web API method that failed, e.g. chat.postMessage.
+	Method string
+	// Code is the "error" field of the response.
+	Code string
+}
+
+func (e *Error) Error() string {
+	if e.Method == "" {
+		return "slack API error: " + e.Code
+	}
+	return "slack API error: " + e.Method + ": " + e.Code
+}
+
+// Is matches errors with the same code, so that errors.Is(err, ErrTokenRevoked) holds
+// whatever method failed.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Permanent reports whether the error is caused by the token or the channel, so that
+// retrying cannot succeed until the connector is fixed.
+func (e *Error) Permanent() bool {
+	switch e.Code {
+	case ErrCodeInvalidAuth, ErrCodeTokenRevoked, ErrCodeAccountInactive, ErrCodeChannelNotFound:
+		return true
+	}
+	return false
+}
+
+// IsPermanent reports whether err is, or wraps, a permanent Slack API error.
+func IsPermanent(err error) bool {
+	var slackErr *Error
+	return errors.As(err, &slackErr) && slackErr.Permanent()
+}
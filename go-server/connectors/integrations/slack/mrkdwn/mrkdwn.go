@@ -0,0 +1,443 @@
+// Package mrkdwn converts CommonMark into Slack's mrkdwn dialect and Block Kit sections.
+//
+// The converter covers the subset of CommonMark callers actually send: ATX headings,
+// paragraphs, fenced code blocks, bullet and ordered lists, block quotes, thematic
+// breaks, emphasis, strong emphasis, strikethrough, code spans, links, images and
+// autolinks. Anything else is passed through as text, with &, < and > escaped.
+package mrkdwn
+
+import (
+	"regexp"
+	"strings"
+
+	"connector-recruitment/go-server/connectors/integrations/slack"
+)
+
+// Escape replaces the three characters Slack reserves for its control sequences.
+func Escape(s string) string {
+	return escaper.Replace(s)
+}
+
+var escaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// FromMarkdown converts CommonMark to a single mrkdwn string.
+func FromMarkdown(md string) string {
+	nodes := parse(md)
+	parts := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		parts = append(parts, n.mrkdwn())
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// BlocksFromMarkdown converts CommonMark to Block Kit blocks: headings become header
+// blocks, and are dropped when they have no text, thematic breaks become dividers and everything else is grouped into mrkdwn
+// sections no longer than slack.MaxSectionTextLength where the content allows it.
+func BlocksFromMarkdown(md string) []slack.Block {
+	var (
+		blocks  []slack.Block
+		section []string
+		size    int
+	)
+	flush := func() {
+		if len(section) > 0 {
+			blocks = append(blocks, slack.SectionBlock(strings.Join(section, "\n\n")))
+			section, size = nil, 0
+		}
+	}
+
+	for _, n := range parse(md) {
+		switch n.kind {
+		case nodeHeading:
+			// Slack rejects a header block without text, and the whole message with it
+			text := truncate(strings.TrimSpace(stripInline(n.text)), slack.MaxHeaderTextLength)
+			if text == "" {
+				continue
+			}
+			flush()
+			blocks = append(blocks, slack.HeaderBlock(text))
+		case nodeBreak:
+			flush()
+			blocks = append(blocks, slack.DividerBlock())
+		default:
+			text := n.mrkdwn()
+			if size > 0 && size+2+len(text) > slack.MaxSectionTextLength {
+				flush()
+			}
+			section = append(section, text)
+			size += len(text) + 2
+		}
+	}
+	flush()
+
+	return blocks
+}
+
+type nodeKind int
+
+const (
+	nodeParagraph nodeKind = iota
+	nodeHeading
+	nodeCode
+	nodeList
+	nodeQuote
+	nodeBreak
+)
+
+type node struct {
+	kind nodeKind
+	// text is the raw markdown of the node; for code blocks it is the verbatim content
+	// and for lists and quotes it holds one line per item with the markers stripped.
+	text  string
+	lines []listLine
+}
+
+type listLine struct {
+	indent string
+	marker string
+	text   string
+}
+
+var (
+	headingRe  = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	fenceRe    = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})(.*)$")
+	bulletRe   = regexp.MustCompile(`^(\s*)[-*+][ \t]+(.*)$`)
+	orderedRe  = regexp.MustCompile(`^(\s*)(\d{1,9})[.)][ \t]+(.*)$`)
+	quoteRe    = regexp.MustCompile(`^ {0,3}>[ ]?(.*)$`)
+	breakRe    = regexp.MustCompile(`^ {0,3}((\*[ \t]*){3,}|(-[ \t]*){3,}|(_[ \t]*){3,})$`)
+	autolinkRe = regexp.MustCompile(`^<((?:[a-zA-Z][a-zA-Z0-9+.-]{1,31}:[^\s<>]*)|(?:[^\s<>@]+@[^\s<>@]+\.[^\s<>@]+))>`)
+)
+
+// parse splits markdown into block level nodes.
+func parse(md string) []node {
+	lines := strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n")
+	var (
+		nodes []node
+		para  []string
+	)
+	flushPara := func() {
+		if len(para) > 0 {
+			nodes = append(nodes, node{kind: nodeParagraph, text: strings.Join(para, "\n")})
+			para = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if m := fenceRe.FindStringSubmatch(line); m != nil {
+			flushPara()
+			fence := m[1]
+			var code []string
+			for i++; i < len(lines); i++ {
+				trimmed := strings.TrimSpace(lines[i])
+				if strings.HasPrefix(trimmed, fence[:3]) && strings.Trim(trimmed, fence[:1]) == "" && len(trimmed) >= len(fence) {
+					break
+				}
+				code = append(code, lines[i])
+			}
+			nodes = append(nodes, node{kind: nodeCode, text: strings.Join(code, "\n")})
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			flushPara()
+			continue
+		}
+
+		if breakRe.MatchString(line) {
+			flushPara()
+			nodes = append(nodes, node{kind: nodeBreak})
+			continue
+		}
+
+		if m := headingRe.FindStringSubmatch(line); m != nil {
+			flushPara()
+			if m[2] != "" {
+				nodes = append(nodes, node{kind: nodeHeading, text: m[2]})
+			}
+			continue
+		}
+
+		if quoteRe.MatchString(line) {
+			flushPara()
+			var quoted []listLine
+			for ; i < len(lines); i++ {
+				m := quoteRe.FindStringSubmatch(lines[i])
+				if m == nil {
+					break
+				}
+				quoted = append(quoted, listLine{text: m[1]})
+			}
+			i--
+			nodes = append(nodes, node{kind: nodeQuote, lines: quoted})
+			continue
+		}
+
+		if bulletRe.MatchString(line) || orderedRe.MatchString(line) {
+			flushPara()
+			var items []listLine
+			for ; i < len(lines); i++ {
+				if m := bulletRe.FindStringSubmatch(lines[i]); m != nil && !breakRe.MatchString(lines[i]) {
+					items = append(items, listLine{indent: listIndent(m[1]), marker: "•", text: m[2]})
+				} else if m := orderedRe.FindStringSubmatch(lines[i]); m != nil {
+					items = append(items, listLine{indent: listIndent(m[1]), marker: m[2] + ".", text: m[3]})
+				} else if len(items) > 0 && strings.TrimSpace(lines[i]) != "" && startsIndented(lines[i]) {
+					// lazy continuation of the previous item
+					items[len(items)-1].text += " " + strings.TrimSpace(lines[i])
+				} else {
+					break
+				}
+			}
+			i--
+			nodes = append(nodes, node{kind: nodeList, lines: items})
+			continue
+		}
+
+		para = append(para, strings.TrimSpace(line))
+	}
+	flushPara()
+
+	return nodes
+}
+
+func (n node) mrkdwn() string {
+	switch n.kind {
+	case nodeHeading:
+		return "*" + inline(n.text) + "*"
+	case nodeCode:
+		return "```\n" + Escape(n.text) + "\n```"
+	case nodeBreak:
+		return "──────────"
+	case nodeList:
+		out := make([]string, 0, len(n.lines))
+		for _, l := range n.lines {
+			out = append(out, l.indent+l.marker+" "+inline(l.text))
+		}
+		return strings.Join(out, "\n")
+	case nodeQuote:
+		out := make([]string, 0, len(n.lines))
+		for _, l := range n.lines {
+			out = append(out, "> "+inline(l.text))
+		}
+		return strings.Join(out, "\n")
+	default:
+		return inline(n.text)
+	}
+}
+
+// inline converts the inline markdown of a single block.
+func inline(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && isPunct(s[i+1]):
+			b.WriteString(Escape(s[i+1 : i+2]))
+			i += 2
+
+		case c == '`':
+			n := runLength(s[i:], '`')
+			delim := s[i : i+n]
+			if end := strings.Index(s[i+n:], delim); end >= 0 {
+				code := s[i+n : i+n+end]
+				if trimmed := strings.TrimSpace(code); trimmed != "" {
+					code = trimmed
+				}
+				b.WriteString("`" + Escape(code) + "`")
+				i += n + end + n
+				continue
+			}
+			b.WriteString(delim)
+			i += n
+
+		case c == '!' && i+1 < len(s) && s[i+1] == '[':
+			if text, url, width, ok := parseLink(s[i+1:]); ok {
+				b.WriteString(link(url, plainInline(text)))
+				i += 1 + width
+				continue
+			}
+			b.WriteByte('!')
+			i++
+
+		case c == '[':
+			if text, url, width, ok := parseLink(s[i:]); ok {
+				b.WriteString(link(url, plainInline(text)))
+				i += width
+				continue
+			}
+			b.WriteString("[")
+			i++
+
+		case c == '<':
+			if m := autolinkRe.FindStringSubmatch(s[i:]); m != nil {
+				target := m[1]
+				if !strings.Contains(target, ":") {
+					target = "mailto:" + target
+				}
+				b.WriteString(link(target, m[1]))
+				i += len(m[0])
+				continue
+			}
+			b.WriteString("&lt;")
+			i++
+
+		case c == '*' || c == '_' || c == '~':
+			n := runLength(s[i:], c)
+			if c == '_' && i > 0 && isWordChar(s[i-1]) {
+				// intraword underscores (snake_case) are never emphasis
+				b.WriteString(s[i : i+n])
+				i += n
+				continue
+			}
+			if out, width, ok := emphasis(s[i:], c, n); ok {
+				b.WriteString(out)
+				i += width
+				continue
+			}
+			b.WriteString(s[i : i+n])
+			i += n
+
+		default:
+			b.WriteString(Escape(s[i : i+1]))
+			i++
+		}
+	}
+	return b.String()
+}
+
+// emphasis converts a delimiter run at the start of s when a matching closing run exists.
+func emphasis(s string, c byte, n int) (string, int, bool) {
+	var open, closeWith string
+	switch {
+	case c == '~' && n >= 2:
+		n, open, closeWith = 2, "~", "~"
+	case c == '~':
+		return "", 0, false
+	case n >= 3:
+		n, open, closeWith = 3, "*_", "_*"
+	case n == 2:
+		open, closeWith = "*", "*"
+	default:
+		open, closeWith = "_", "_"
+	}
+
+	delim := strings.Repeat(string(c), n)
+	rest := s[n:]
+	if rest == "" || rest[0] == ' ' {
+		return "", 0, false
+	}
+	for from := 0; from < len(rest); {
+		end := strings.Index(rest[from:], delim)
+		if end < 0 {
+			return "", 0, false
+		}
+		end += from
+		run := runLength(rest[end:], c)
+		if run != n {
+			// a longer run, like the "**" of a strong span inside an emphasis, is
+			// nested formatting rather than the closing delimiter
+			from = end + run
+			continue
+		}
+		inner := rest[:end]
+		after := end + n
+		closes := inner != "" && inner[len(inner)-1] != ' ' &&
+			(c != '_' || after >= len(rest) || !isWordChar(rest[after]))
+		if closes {
+			return open + inline(inner) + closeWith, n + after, true
+		}
+		from = end + n
+	}
+	return "", 0, false
+}
+
+// parseLink parses "[text](url)" or `[text](url "title")` at the start of s.
+func parseLink(s string) (text, url string, width int, ok bool) {
+	depth := 0
+	closeText := -1
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				closeText = i
+			}
+		}
+		if closeText >= 0 {
+			break
+		}
+	}
+	if closeText < 0 || closeText+1 >= len(s) || s[closeText+1] != '(' {
+		return "", "", 0, false
+	}
+	end := strings.IndexByte(s[closeText+2:], ')')
+	if end < 0 {
+		return "", "", 0, false
+	}
+	dest := strings.TrimSpace(s[closeText+2 : closeText+2+end])
+	if sp := strings.IndexAny(dest, " \t"); sp >= 0 {
+		dest = dest[:sp] // drop the optional title
+	}
+	dest = strings.TrimSuffix(strings.TrimPrefix(dest, "<"), ">")
+	if dest == "" {
+		return "", "", 0, false
+	}
+	return s[1:closeText], dest, closeText + 2 + end + 1, true
+}
+
+// link renders a Slack link. Slack has no escape for "|" in the label, so it is replaced.
+func link(url, label string) string {
+	url = strings.NewReplacer("&", "&amp;", "<", "%3C", ">", "%3E", "|", "%7C", " ", "%20").Replace(url)
+	if label == "" || label == url {
+		return "<" + url + ">"
+	}
+	return "<" + url + "|" + strings.ReplaceAll(label, "|", "¦") + ">"
+}
+
+// stripInline drops inline formatting markers, for header blocks which are plain text.
+func stripInline(s string) string {
+	return strings.NewReplacer("**", "", "__", "", "~~", "", "`", "").Replace(s)
+}
+
+// plainInline renders inline markdown without formatting, for link labels.
+func plainInline(s string) string {
+	return Escape(stripInline(s))
+}
+
+func truncate(s string, max int) string {
+	if r := []rune(s); len(r) > max {
+		return string(r[:max-1]) + "…"
+	}
+	return s
+}
+
+// listIndent normalises nested list indentation to four spaces per level.
+func listIndent(ws string) string {
+	width := len(strings.ReplaceAll(ws, "\t", "    "))
+	return strings.Repeat("    ", width/2)
+}
+
+func startsIndented(line string) bool {
+	return strings.HasPrefix(line, "  ") || strings.HasPrefix(line, "\t")
+}
+
+func runLength(s string, c byte) int {
+	n := 0
+	for n < len(s) && s[n] == c {
+		n++
+	}
+	return n
+}
+
+func isPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,193 @@
+package mrkdwn
+
+import (
+	"strings"
+	"testing"
+
+	"connector-recruitment/go-server/connectors/integrations/slack"
+)
+
+func TestFromMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		want string
+	}{
+		// emphasis
+		{"emphasis", "*em* and _em_", "_em_ and _em_"},
+		{"strong", "**strong** and __strong__", "*strong* and *strong*"},
+		{"strong emphasis", "***both***", "*_both_*"},
+		{"strikethrough", "~~gone~~", "~gone~"},
+		{"emphasis in strong", "**bold _and em_ inside**", "*bold _and em_ inside*"},
+		{"strong in emphasis", "*em **strong** em*", "_em *strong* em_"},
+		{"intraword underscores", "snake_case_name", "snake_case_name"},
+		{"lone asterisks", "2 * 3 * 4", "2 * 3 * 4"},
+		{"unclosed strong", "**open", "**open"},
+		{"space after opener", "** not strong**", "** not strong**"},
+
+		// links
+		{"link", "[site](https://example.com)", "<https://example.com|site>"},
+		{"link with title", `[site](https://example.com "title")`, "<https://example.com|site>"},
+		{"formatted label", "[**bold** label](https://example.com)", "<https://example.com|bold label>"},
+		{"url with ampersand", "[q](https://example.com/a?b=1&c=2)", "<https://example.com/a?b=1&amp;c=2|q>"},
+		{"pipes", "[a|b](https://example.com/p|q)", "<https://example.com/p%7Cq|a¦b>"},
+		{"image", "![alt](https://example.com/i.png)", "<https://example.com/i.png|alt>"},
+		{"autolink", "<https://example.com>", "<https://example.com>"},
+		{"email autolink", "<me@example.com>", "<mailto:me@example.com|me@example.com>"},
+		{"not a link", "[broken](", "[broken]("},
+
+		// lists
+		{"bullets", "- one\n* two\n+ three", "• one\n• two\n• three"},
+		{"nested bullets", "- one\n  - nested\n    - deeper", "• one\n    • nested\n        • deeper"},
+		{"ordered", "1. first\n2) second", "1. first\n2. second"},
+		{"continuation", "1. first\n   continued", "1. first continued"},
+		{"formatted item", "- **bold** item", "• *bold* item"},
+		{"list then paragraph", "- item\nafter", "• item\n\nafter"},
+
+		// code
+		{"code span", "`*not em* [a](b)`", "`*not em* [a](b)`"},
+		{"code span escapes", "`a\\_b`", "`a\\_b`"},
+		{"code span entities", "`a < b && c`", "`a &lt; b &amp;&amp; c`"},
+		{"unclosed code span", "`open *em*", "`open _em_"},
+		{"fence", "```go\n*x* [a](b)\n\\_ __y__\n```", "```\n*x* [a](b)\n\\_ __y__\n```"},
+		{"tilde fence", "~~~\n~~not struck~~\n~~~", "```\n~~not struck~~\n```"},
+		{"fence entities", "```\nif a < b && c > d {}\n```", "```\nif a &lt; b &amp;&amp; c &gt; d {}\n```"},
+		{"unclosed fence", "```\ncode\n\nmore", "```\ncode\n\nmore\n```"},
+
+		// escaping
+		{"reserved characters", "a & b < c > d", "a &amp; b &lt; c &gt; d"},
+		{"entities", "&amp; &lt;", "&amp;amp; &amp;lt;"},
+		{"escaped brackets", `\<tag\>`, "&lt;tag&gt;"},
+		{"mention lookalike", "<@U123> <!channel>", "&lt;@U123&gt; &lt;!channel&gt;"},
+
+		// blocks
+		{"heading", "## Title *em*", "*Title _em_*"},
+		{"empty heading", "above\n\n#\n\n## \n\nbelow", "above\n\nbelow"},
+		{"quote", "> quoted *em*\n> a < b", "> quoted _em_\n> a &lt; b"},
+		{"break", "above\n\n---\n\nbelow", "above\n\n──────────\n\nbelow"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromMarkdown(tt.md); got != tt.want {
+				t.Errorf("FromMarkdown(%q) =\n%q\nwant\n%q", tt.md, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain", "plain"},
+		{"a & b", "a &amp; b"},
+		{"<tag>", "&lt;tag&gt;"},
+		{"&lt;", "&amp;lt;"},
+		{"*_~`", "*_~`"},
+	}
+	for _, tt := range tests {
+		if got := Escape(tt.in); got != tt.want {
+			t.Errorf("Escape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestBlocksFromMarkdown(t *testing.T) {
+	paragraph := strings.Repeat("x", 1000)
+	longHeading := strings.Repeat("é", 200)
+	tests := []struct {
+		name string
+		md   string
+		want []slack.Block
+	}{
+		{
+			name: "headings and breaks",
+			md:   "# Title **bold**\n\nsome *text*\n\n---\n\n- item",
+			want: []slack.Block{
+				slack.HeaderBlock("Title bold"),
+				slack.SectionBlock("some _text_"),
+				slack.DividerBlock(),
+				slack.SectionBlock("• item"),
+			},
+		},
+		{
+			name: "grouped sections",
+			md:   strings.Repeat(paragraph+"\n\n", 5),
+			want: []slack.Block{
+				slack.SectionBlock(paragraph + "\n\n" + paragraph),
+				slack.SectionBlock(paragraph + "\n\n" + paragraph),
+				slack.SectionBlock(paragraph),
+			},
+		},
+		{
+			name: "long heading",
+			md:   "# " + longHeading,
+			want: []slack.Block{slack.HeaderBlock(strings.Repeat("é", slack.MaxHeaderTextLength-1) + "…")},
+		},
+		{
+			name: "oversized node",
+			md:   "short\n\n```\n" + strings.Repeat("y", slack.MaxSectionTextLength) + "\n```",
+			want: []slack.Block{
+				slack.SectionBlock("short"),
+				slack.SectionBlock("```\n" + strings.Repeat("y", slack.MaxSectionTextLength) + "\n```"),
+			},
+		},
+		{
+			name: "empty headings",
+			md:   "#\n\n## \n\n### **\n\ntext",
+			want: []slack.Block{slack.SectionBlock("text")},
+		},
+		{
+			name: "empty",
+			md:   "\n\n",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BlocksFromMarkdown(tt.md)
+			if len(got) != len(tt.want) {
+				t.Fatalf("BlocksFromMarkdown returned %d blocks, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				if !sameBlock(got[i], tt.want[i]) {
+					t.Errorf("block %d = %+v, want %+v", i, blockString(got[i]), blockString(tt.want[i]))
+				}
+				if got[i].Type == slack.BlockTypeHeader && len([]rune(got[i].Text.Text)) > slack.MaxHeaderTextLength {
+					t.Errorf("header block %d is over %d characters", i, slack.MaxHeaderTextLength)
+				}
+			}
+		})
+	}
+}
+
+func TestBlocksFromMarkdownSectionLimit(t *testing.T) {
+	var md strings.Builder
+	for i := range 200 {
+		md.WriteString(strings.Repeat("word ", 10+i%40))
+		md.WriteString("\n\n")
+	}
+	for i, b := range BlocksFromMarkdown(md.String()) {
+		if b.Type != slack.BlockTypeSection {
+			t.Fatalf("block %d is a %s, want a section", i, b.Type)
+		}
+		if len(b.Text.Text) > slack.MaxSectionTextLength {
+			t.Errorf("section %d is %d bytes, over the limit of %d", i, len(b.Text.Text), slack.MaxSectionTextLength)
+		}
+	}
+}
+
+func sameBlock(a, b slack.Block) bool {
+	if a.Type != b.Type || (a.Text == nil) != (b.Text == nil) {
+		return false
+	}
+	return a.Text == nil || *a.Text == *b.Text
+}
+
+func blockString(b slack.Block) string {
+	if b.Text == nil {
+		return b.Type
+	}
+	return b.Type + " " + b.Text.Type + " " + b.Text.Text
+}
@@ -0,0 +1,183 @@
+package slack
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxMessageTextLength is the longest text sent in a single message. Slack truncates
+	// text past 40,000 characters and recommends staying under 4,000.
+	MaxMessageTextLength = 4000
+	// MaxMessageBlocks is the most blocks Slack accepts in a single message.
+	MaxMessageBlocks = 50
+
+	codeFence = "```"
+	// fenceReserve leaves room to close a code block at the end of a chunk and reopen it
+	// at the start of the next.
+	fenceReserve = len("\n" + codeFence)
+)
+
+// SplitMessage splits msg into messages that fit Slack's text and block limits. Text is
+// split on paragraph, then line, then word boundaries, the space of a word boundary
+// staying at the end of the first chunk; a code block that spans two
+// messages is closed at the end of the first and reopened in the second. Messages with
+// blocks are split into groups of at most MaxMessageBlocks blocks, each with its own
+// notification text.
+func SplitMessage(msg Message) []Message {
+	if len(msg.Blocks) > 0 {
+		return splitBlocksMessage(msg)
+	}
+
+	var chunks []string
+	if msg.Mrkdwn != nil && !*msg.Mrkdwn {
+		// literal text has no code blocks to keep intact
+		chunks = pack(msg.Text, MaxMessageTextLength, textSeparators)
+	} else {
+		chunks = SplitText(msg.Text, MaxMessageTextLength)
+	}
+	parts := make([]Message, 0, len(chunks))
+	for _, chunk := range chunks {
+		part := msg
+		part.Text = chunk
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+func splitBlocksMessage(msg Message) []Message {
+	var blocks []Block
+	for _, b := range msg.Blocks {
+		if b.Type != BlockTypeSection || b.Text == nil || len(b.Text.Text) <= MaxSectionTextLength {
+			blocks = append(blocks, b)
+			continue
+		}
+		for _, chunk := range SplitText(b.Text.Text, MaxSectionTextLength) {
+			blocks = append(blocks, Block{Type: b.Type, Text: &TextBlock{Type: b.Text.Type, Text: chunk}})
+		}
+	}
+
+	if len(blocks) <= MaxMessageBlocks && len(msg.Text) <= MaxMessageTextLength {
+		part := msg
+		part.Blocks = blocks
+		return []Message{part}
+	}
+
+	var parts []Message
+	for len(blocks) > 0 {
+		n := min(len(blocks), MaxMessageBlocks)
+		part := msg
+		part.Blocks = blocks[:n]
+		part.Text = fallbackText(blocks[:n])
+		parts = append(parts, part)
+		blocks = blocks[n:]
+	}
+	return parts
+}
+
+// fallbackText is the notification text of a group of blocks.
+func fallbackText(blocks []Block) string {
+	var texts []string
+	for _, b := range blocks {
+		if b.Text != nil {
+			texts = append(texts, b.Text.Text)
+		}
+	}
+	return SplitText(strings.Join(texts, "\n\n"), MaxMessageTextLength)[0]
+}
+
+// textSeparators are the boundaries text is split on, from the most to the least preferred.
+var textSeparators = []string{"\n\n", "\n", " "}
+
+// SplitText splits text into chunks of at most limit bytes, keeping code blocks intact
+// across chunks by closing and reopening their fences.
+func SplitText(text string, limit int) []string {
+	if len(text) <= limit {
+		return []string{text}
+	}
+
+	chunks := pack(text, limit-2*fenceReserve, textSeparators)
+
+	inFence := false
+	for i, chunk := range chunks {
+		if inFence {
+			chunk = codeFence + "\n" + chunk
+		}
+		inFence = endsInsideFence(chunk)
+		if inFence {
+			chunk += "\n" + codeFence
+		}
+		chunks[i] = chunk
+	}
+	return chunks
+}
+
+// pack greedily joins the pieces of text separated by seps[0] into chunks of at most
+// limit bytes. Pieces that are too long on their own are split by the next separator,
+// and as a last resort at a rune boundary. Line breaks between chunks are dropped, but
+// a space is kept at the end of the chunk before it: inside a code block it is part of
+// the code.
+func pack(text string, limit int, seps []string) []string {
+	if len(text) <= limit {
+		return []string{text}
+	}
+	if len(seps) == 0 {
+		return hardSplit(text, limit)
+	}
+
+	sep := seps[0]
+	pieces := strings.Split(text, sep)
+	if sep == " " {
+		pieces, sep = strings.SplitAfter(text, sep), ""
+	}
+	var (
+		chunks  []string
+		current string
+	)
+	for _, piece := range pieces {
+		if current != "" && len(current)+len(sep)+len(piece) <= limit {
+			current += sep + piece
+			continue
+		}
+		if current != "" {
+			chunks = append(chunks, current)
+			current = ""
+		}
+		if len(piece) <= limit {
+			current = piece
+			continue
+		}
+		sub := pack(piece, limit, seps[1:])
+		chunks = append(chunks, sub[:len(sub)-1]...)
+		current = sub[len(sub)-1]
+	}
+	if current != "" {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+func hardSplit(text string, limit int) []string {
+	var chunks []string
+	for len(text) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		chunks = append(chunks, text[:cut])
+		text = text[cut:]
+	}
+	return append(chunks, text)
+}
+
+// endsInsideFence reports whether a chunk leaves a code block open. A line opens or
+// closes a block only when it holds an odd number of fences: "```code```" does neither.
+func endsInsideFence(chunk string) bool {
+	open := false
+	for _, line := range strings.Split(chunk, "\n") {
+		if strings.Count(line, codeFence)%2 == 1 {
+			open = !open
+		}
+	}
+	return open
+}
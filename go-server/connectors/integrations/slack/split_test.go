@@ -0,0 +1,217 @@
+package slack
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEndsInsideFence(t *testing.T) {
+	tests := []struct {
+		chunk string
+		want  bool
+	}{
+		{"plain text", false},
+		{"```\ncode", true},
+		{"```go\ncode\n```", false},
+		{"```code```", false},
+		{"```code```\nmore text", false},
+		{"text ```code", true},
+		{"```\na\n```\n```\nb", true},
+		{"```code```\n```\nopen", true},
+	}
+	for _, tt := range tests {
+		if got := endsInsideFence(tt.chunk); got != tt.want {
+			t.Errorf("endsInsideFence(%q) = %v, want %v", tt.chunk, got, tt.want)
+		}
+	}
+}
+
+func TestSplitText(t *testing.T) {
+	const limit = 40
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "short text",
+			text: "hello world",
+			want: []string{"hello world"},
+		},
+		{
+			name: "paragraphs",
+			text: "first paragraph here\n\nsecond paragraph here",
+			want: []string{"first paragraph here", "second paragraph here"},
+		},
+		{
+			name: "lines",
+			text: "first line is long enough\nsecond line is long too",
+			want: []string{"first line is long enough", "second line is long too"},
+		},
+		{
+			name: "words keep their space",
+			text: "alpha beta gamma delta epsilon zeta eta theta",
+			want: []string{"alpha beta gamma delta epsilon ", "zeta eta theta"},
+		},
+		{
+			name: "code block across chunks",
+			text: "```\nline one of code\nline two of code\n```",
+			want: []string{"```\nline one of code\n```", "```\nline two of code\n```"},
+		},
+		{
+			name: "words in a code block keep their space",
+			text: "```\nx := a + b\ny := alpha + beta + gamma + delta\n```",
+			want: []string{"```\nx := a + b\n```", "```\ny := alpha + beta + gamma + \n```", "```\ndelta\n```"},
+		},
+		{
+			name: "inline code block",
+			text: "see ```a := 1``` here\n\nthen the rest of the text",
+			want: []string{"see ```a := 1``` here", "then the rest of the text"},
+		},
+		{
+			name: "long word",
+			text: strings.Repeat("x", 50),
+			want: []string{strings.Repeat("x", 32), strings.Repeat("x", 18)},
+		},
+		{
+			name: "runes",
+			text: strings.Repeat("é", 25),
+			want: []string{strings.Repeat("é", 16), strings.Repeat("é", 9)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitText(tt.text, limit)
+			if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tt.want) {
+				t.Errorf("SplitText(%q, %d) =\n%q\nwant\n%q", tt.text, limit, got, tt.want)
+			}
+			for _, chunk := range got {
+				if len(chunk) > limit {
+					t.Errorf("chunk %q is %d bytes, over the limit of %d", chunk, len(chunk), limit)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitTextKeepsCode(t *testing.T) {
+	var code strings.Builder
+	for i := range 500 {
+		fmt.Fprintf(&code, "fmt.Println(%d, \"some output\")\n", i)
+	}
+	text := "Before\n\n```\n" + code.String() + "```\n\nAfter"
+
+	chunks := SplitText(text, MaxMessageTextLength)
+	if len(chunks) < 2 {
+		t.Fatalf("SplitText returned %d chunk, want several", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if len(chunk) > MaxMessageTextLength {
+			t.Errorf("chunk %d is %d bytes, over the limit", i, len(chunk))
+		}
+		if endsInsideFence(chunk) {
+			t.Errorf("chunk %d leaves a code block open", i)
+		}
+	}
+	// only fences and blank lines are added or dropped
+	if got, want := textLines(strings.Join(chunks, "\n")), textLines(text); got != want {
+		t.Errorf("the chunks hold\n%s\nwant\n%s", got, want)
+	}
+}
+
+// textLines drops from text the lines that are blank or only a fence.
+func textLines(text string) string {
+	var kept []string
+	for _, line := range strings.Split(text, "\n") {
+		if line != codeFence && line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+func TestSplitMessageLiteral(t *testing.T) {
+	mrkdwn := false
+	text := strings.Repeat("```word ", 1000)
+	parts := SplitMessage(Message{Channel: "C1", Text: text, Mrkdwn: &mrkdwn})
+	if len(parts) != 2 {
+		t.Fatalf("SplitMessage returned %d parts, want 2", len(parts))
+	}
+	var joined string
+	for _, part := range parts {
+		if part.Channel != "C1" || part.Mrkdwn != &mrkdwn {
+			t.Errorf("part %+v lost the fields of the message", part)
+		}
+		if len(part.Text) > MaxMessageTextLength {
+			t.Errorf("part is %d bytes, over the limit", len(part.Text))
+		}
+		joined += part.Text
+	}
+	// literal text gets no fence repair
+	if joined != text {
+		t.Errorf("the parts do not rebuild the text")
+	}
+}
+
+func TestSplitMessageBlocks(t *testing.T) {
+	section := func(text string) Block { return SectionBlock(text) }
+
+	t.Run("fits", func(t *testing.T) {
+		msg := Message{Channel: "C1", Text: "summary", Blocks: []Block{HeaderBlock("title"), section("body"), DividerBlock()}}
+		parts := SplitMessage(msg)
+		if len(parts) != 1 || len(parts[0].Blocks) != 3 || parts[0].Text != "summary" {
+			t.Errorf("SplitMessage(%+v) = %+v, want the message unchanged", msg, parts)
+		}
+	})
+
+	t.Run("long section", func(t *testing.T) {
+		long := strings.Repeat("a sentence of the section. ", 200)
+		parts := SplitMessage(Message{Channel: "C1", Text: "summary", Blocks: []Block{section(long)}})
+		if len(parts) != 1 {
+			t.Fatalf("SplitMessage returned %d parts, want 1", len(parts))
+		}
+		blocks := parts[0].Blocks
+		if len(blocks) != 2 {
+			t.Fatalf("the section was split into %d blocks, want 2", len(blocks))
+		}
+		var joined string
+		for _, b := range blocks {
+			if b.Type != BlockTypeSection || b.Text.Type != TextTypeMrkdwn {
+				t.Errorf("block %+v is not a mrkdwn section", b)
+			}
+			if len(b.Text.Text) > MaxSectionTextLength {
+				t.Errorf("section is %d bytes, over the limit", len(b.Text.Text))
+			}
+			joined += b.Text.Text
+		}
+		if joined != long {
+			t.Errorf("the sections do not rebuild the text")
+		}
+	})
+
+	t.Run("many blocks", func(t *testing.T) {
+		var blocks []Block
+		for i := range 120 {
+			blocks = append(blocks, section(fmt.Sprintf("block %d", i)))
+		}
+		parts := SplitMessage(Message{Channel: "C1", Text: "summary", Blocks: blocks})
+		if len(parts) != 3 {
+			t.Fatalf("SplitMessage returned %d parts, want 3", len(parts))
+		}
+		wantBlocks := []int{50, 50, 20}
+		next := 0
+		for i, part := range parts {
+			if len(part.Blocks) != wantBlocks[i] {
+				t.Errorf("part %d has %d blocks, want %d", i, len(part.Blocks), wantBlocks[i])
+			}
+			if want := fmt.Sprintf("block %d", next); part.Blocks[0].Text.Text != want {
+				t.Errorf("part %d starts with %q, want %q", i, part.Blocks[0].Text.Text, want)
+			}
+			if !strings.HasPrefix(part.Text, fmt.Sprintf("block %d\n\n", next)) {
+				t.Errorf("part %d has text %q, want the text of its blocks", i, part.Text)
+			}
+			next += len(part.Blocks)
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"connector-recruitment/go-server/connectors/logger"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+
+	DefaultTimeout = 10 * time.Second
+	retryBackoff   = 500 * time.Millisecond
+)
+
+type HttpClient interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+type Client struct {
+	httpClient HttpClient
+	logger     logger.Logger
+	// backoff is the wait before the first retry, and grows by as much before each next one.
+	backoff time.Duration
+}
+
+func NewClient(httpClient HttpClient, logger logger.Logger) *Client {
+	return &Client{
+		httpClient: httpClient,
+		logger:     logger,
+		backoff:    retryBackoff,
+	}
+}
+
+// Envelope is the JSON body POSTed to a webhook endpoint.
+type Envelope struct {
+	ConnectorID string    `json:"connector_id"`
+	TenantID    string    `json:"tenant_id"`
+	Message     string    `json:"message"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// Request describes a single webhook delivery.
+type Request struct {
+	URL        string
+	Secret     string
+	Headers    map[string]string
+	MaxRetries int
+	Envelope   Envelope
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of "<timestamp>.<body>" keyed by secret.
+// Receivers recompute it from the TimestampHeader and raw body to verify a delivery.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send POSTs the envelope to the webhook URL, retrying transport errors, 429 and 5xx
+// responses up to MaxRetries times. It returns the status code of the last attempt.
+func (c *Client) Send(ctx context.Context, r Request) (int, error) {
+	body, err := json.Marshal(r.Envelope)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	var (
+		statusCode int
+		lastErr    error
+	)
+	for attempt := 0; attempt <= r.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return statusCode, ctx.Err()
+			case <-time.After(time.Duration(attempt) * c.backoff):
+			}
+		}
+
+		statusCode, lastErr = c.post(ctx, r, body)
+		if lastErr == nil {
+			c.logger.Info("Webhook delivered successfully", "connector-id", r.Envelope.ConnectorID, "status", statusCode, "attempt", attempt+1)
+			return statusCode, nil
+		}
+		if statusCode != 0 && !retryable(statusCode) {
+			break
+		}
+		c.logger.Warn("Webhook delivery attempt failed", "connector-id", r.Envelope.ConnectorID, "attempt", attempt+1, "err", lastErr)
+	}
+
+	return statusCode, lastErr
+}
+
+func (c *Client) post(ctx context.Context, r Request, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// headers: custom ones first so they can never override the signature
+	for k, v := range r.Headers {
+		req.Header.Set(k, v)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(r.Secret, timestamp, body))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook endpoint returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func retryable(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
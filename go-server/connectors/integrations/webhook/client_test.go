@@ -0,0 +1,194 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// endpoint is a webhook receiver answering with the next of its statuses, then with the
+// last one, and keeping the requests it got.
+type endpoint struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newEndpoint(t *testing.T, statuses ...int) *endpoint {
+	e := &endpoint{statuses: statuses}
+	e.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		e.mu.Lock()
+		status := e.statuses[min(len(e.requests), len(e.statuses)-1)]
+		e.requests = append(e.requests, r)
+		e.bodies = append(e.bodies, body)
+		e.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(e.Close)
+	return e
+}
+
+func (e *endpoint) attempts() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.requests)
+}
+
+func newTestClient() *Client {
+	c := NewClient(http.DefaultClient, discard)
+	c.backoff = time.Millisecond
+	return c
+}
+
+func TestSign(t *testing.T) {
+	// printf '1700000000.{"a":1}' | openssl dgst -sha256 -hmac secret
+	got := Sign("secret", "1700000000", []byte(`{"a":1}`))
+	want := "sha256=" + "49f24e537407743fa4a0242bb63b94b9a47ee99cbbe071ccd8a22550ae411686"
+	if got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+	if Sign("other", "1700000000", []byte(`{"a":1}`)) == got {
+		t.Error("Sign ignores the secret")
+	}
+	if Sign("secret", "1700000001", []byte(`{"a":1}`)) == got {
+		t.Error("Sign ignores the timestamp")
+	}
+}
+
+func TestSendSignsTheBody(t *testing.T) {
+	e := newEndpoint(t, http.StatusNoContent)
+	envelope := Envelope{ConnectorID: "c1", TenantID: "t1", Message: "hello", Timestamp: time.Unix(1700000000, 0).UTC()}
+	status, err := newTestClient().Send(context.Background(), Request{
+		URL:      e.URL,
+		Secret:   "secret",
+		Envelope: envelope,
+		// custom headers, in any case, cannot replace the signature
+		Headers: map[string]string{
+			"x-webhook-signature": "sha256=forged",
+			TimestampHeader:       "1",
+			"Authorization":       "Bearer key",
+		},
+	})
+	if err != nil || status != http.StatusNoContent {
+		t.Fatalf("Send = %d, %v, want %d", status, err, http.StatusNoContent)
+	}
+
+	r, body := e.requests[0], e.bodies[0]
+	var got Envelope
+	if err := json.Unmarshal(body, &got); err != nil || got != envelope {
+		t.Errorf("body = %s (%v), want the envelope %+v", body, err, envelope)
+	}
+	timestamp := r.Header.Get(TimestampHeader)
+	if ts, err := strconv.ParseInt(timestamp, 10, 64); err != nil || time.Since(time.Unix(ts, 0)) > time.Minute {
+		t.Errorf("%s = %q, want the current unix time", TimestampHeader, timestamp)
+	}
+	if got, want := r.Header.Get(SignatureHeader), Sign("secret", timestamp, body); got != want {
+		t.Errorf("%s = %q, want %q", SignatureHeader, got, want)
+	}
+	if len(r.Header.Values(SignatureHeader)) != 1 || len(r.Header.Values(TimestampHeader)) != 1 {
+		t.Errorf("the signature headers were sent several times: %v", r.Header)
+	}
+	if got := r.Header.Get("Authorization"); got != "Bearer key" {
+		t.Errorf("Authorization = %q, want the custom header", got)
+	}
+	if got := r.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+}
+
+func TestSendRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		maxRetries   int
+		wantStatus   int
+		wantAttempts int
+		wantErr      bool
+	}{
+		{"success", []int{200}, 3, 200, 1, false},
+		{"server error then success", []int{503, 500, 200}, 3, 200, 3, false},
+		{"too many requests then success", []int{429, 201}, 1, 201, 2, false},
+		{"retries exhausted", []int{502}, 2, 502, 3, true},
+		{"no retries", []int{500}, 0, 500, 1, true},
+		{"client error", []int{400, 200}, 3, 400, 1, true},
+		{"not found", []int{404, 200}, 3, 404, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEndpoint(t, tt.statuses...)
+			status, err := newTestClient().Send(context.Background(), Request{URL: e.URL, Secret: "s", MaxRetries: tt.maxRetries})
+			if (err != nil) != tt.wantErr || status != tt.wantStatus {
+				t.Errorf("Send = %d, %v, want %d (error %v)", status, err, tt.wantStatus, tt.wantErr)
+			}
+			if got := e.attempts(); got != tt.wantAttempts {
+				t.Errorf("the endpoint got %d attempts, want %d", got, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+// failingClient fails every request with a transport error.
+type failingClient struct{ calls int }
+
+func (c *failingClient) Do(*http.Request) (*http.Response, error) {
+	c.calls++
+	return nil, errors.New("connection reset")
+}
+
+func TestSendRetriesTransportErrors(t *testing.T) {
+	httpClient := &failingClient{}
+	c := NewClient(httpClient, discard)
+	c.backoff = time.Millisecond
+
+	status, err := c.Send(context.Background(), Request{URL: "http://example.invalid", Secret: "s", MaxRetries: 2})
+	if err == nil || status != 0 {
+		t.Fatalf("Send = %d, %v, want a transport error", status, err)
+	}
+	if httpClient.calls != 3 {
+		t.Errorf("Send made %d attempts, want 3", httpClient.calls)
+	}
+}
+
+func TestSendStopsOnCancel(t *testing.T) {
+	e := newEndpoint(t, http.StatusServiceUnavailable)
+	c := newTestClient()
+	c.backoff = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := c.Send(ctx, Request{URL: e.URL, Secret: "s", MaxRetries: 5})
+		done <- err
+	}()
+
+	// cancel while Send waits before its first retry
+	for e.attempts() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Send = %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Send kept waiting after the context was cancelled")
+	}
+	if got := e.attempts(); got != 1 {
+		t.Errorf("the endpoint got %d attempts, want 1", got)
+	}
+}
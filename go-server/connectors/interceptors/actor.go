@@ -0,0 +1,40 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+
+	"connector-recruitment/go-server/connectors/audit"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// maxActorLength matches the audit_events.actor column.
+const maxActorLength = 255
+
+// ActorUnaryInterceptor puts the audit.Actor of the request in its context: the
+// audit.ActorHeader metadata, the peer address and the RPC method.
+func ActorUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		actor := audit.Actor{ID: audit.AnonymousActor, Method: info.FullMethod}
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(audit.ActorHeader); len(values) > 0 && values[0] != "" {
+				actor.ID = values[0]
+				if len(actor.ID) > maxActorLength {
+					actor.ID = strings.ToValidUTF8(actor.ID[:maxActorLength], "")
+				}
+			}
+		}
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			actor.PeerAddress = p.Addr.String()
+		}
+		return handler(audit.WithActor(ctx, actor), req)
+	}
+}
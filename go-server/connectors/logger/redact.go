@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces the values that must not be logged.
+const Redacted = "[REDACTED]"
+
+// DefaultSensitiveKeys are the attribute keys whose values are always redacted.
+var DefaultSensitiveKeys = []string{"token", "password", "secret", "authorization", "api_key", "kek"}
+
+// slackToken matches slack bot, user and app-level tokens.
+var slackToken = regexp.MustCompile(`\b(xoxb|xoxp|xapp)-[A-Za-z0-9-]+`)
+
+// RedactString replaces the slack tokens found in s.
+func RedactString(s string) string {
+	return slackToken.ReplaceAllString(s, "$1-"+Redacted)
+}
+
+// RedactingHandler wraps a slog.Handler and scrubs secrets from every record: the values
+// of sensitive keys are replaced, and slack tokens are cut out of the message and of all
+// attributes, including errors and nested groups.
+type RedactingHandler struct {
+	next slog.Handler
+	keys map[string]bool
+}
+
+// NewRedactingHandler wraps next. Keys are matched case-insensitively, with '-' and '_'
+// considered equal; they are added to DefaultSensitiveKeys.
+func NewRedactingHandler(next slog.Handler, sensitiveKeys []string) *RedactingHandler {
+	keys := make(map[string]bool, len(DefaultSensitiveKeys)+len(sensitiveKeys))
+	for _, k := range append(DefaultSensitiveKeys, sensitiveKeys...) {
+		if k = normalizeKey(k); k != "" {
+			keys[k] = true
+		}
+	}
+	return &RedactingHandler{next: next, keys: keys}
+}
+
+func (h *RedactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *RedactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, RedactString(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(h.redact(a))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *RedactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.redact(a)
+	}
+	return &RedactingHandler{next: h.next.WithAttrs(redacted), keys: h.keys}
+}
+
+func (h *RedactingHandler) WithGroup(name string) slog.Handler {
+	return &RedactingHandler{next: h.next.WithGroup(name), keys: h.keys}
+}
+
+// redact resolves LogValuers first, so that what they return is scrubbed too.
+func (h *RedactingHandler) redact(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	if h.keys[normalizeKey(a.Key)] {
+		return slog.String(a.Key, Redacted)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(RedactString(a.Value.String()))
+	case slog.KindGroup:
+		group := a.Value.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			redacted[i] = h.redact(ga)
+		}
+		a.Value = slog.GroupValue(redacted...)
+	case slog.KindAny:
+		switch v := a.Value.Any().(type) {
+		case nil:
+		case error:
+			a.Value = slog.StringValue(RedactString(v.Error()))
+		default:
+			// Other values are marshalled by the wrapped handler; only those that print a
+			// token are replaced by their redacted text.
+			if s := fmt.Sprintf("%+v", v); slackToken.MatchString(s) {
+				a.Value = slog.StringValue(RedactString(s))
+			}
+		}
+	}
+	return a
+}
+
+func normalizeKey(key string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(key)), "-", "_")
+}
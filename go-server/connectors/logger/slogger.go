@@ -25,11 +25,13 @@ func getLogLevel(env config.Env) slog.Level {
 	return level
 }
 
-// NewProductionLogger returns a logger configured to output JSON formatted logs.
+// NewProductionLogger returns a logger configured to output JSON formatted logs, with
+// secrets redacted.
 func NewProductionLogger(env config.Env) *slog.Logger {
-	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+	var handler slog.Handler = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: getLogLevel(env), // Set the log level to info.
 	})
+	handler = NewRedactingHandler(handler, env.LogRedactKeys)
 	newLogger := slog.New(handler).With("service", env.Name)
 	return newLogger
 }
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	pb "connector-recruitment/go-server/connectors/genproto"
+	"connector-recruitment/go-server/connectors/storage"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ListAuditEvents returns a page of the audit log, newest first, and the token of the next
+// page, which is empty on the last page. Paging works as in ListMessages.
+func (s *ConnectorService) ListAuditEvents(ctx context.Context, req *pb.ListAuditEventsRequest) ([]*pb.AuditEvent, string, error) {
+	filter := storage.AuditFilter{
+		WorkspaceID: req.TenantId,
+		ConnectorID: req.ConnectorId,
+		Actor:       req.Actor,
+		Limit:       int(req.PageSize),
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultMessagesPageSize
+	}
+	if filter.Limit > maxMessagesPageSize {
+		filter.Limit = maxMessagesPageSize
+	}
+	if req.StartTime != nil {
+		filter.From = req.StartTime.AsTime()
+	}
+	if req.EndTime != nil {
+		filter.To = req.EndTime.AsTime()
+	}
+	if req.PageToken != "" {
+		cursor, err := decodeMessageCursor(req.PageToken)
+		if err != nil {
+			return nil, "", err
+		}
+		filter.After = cursor
+	}
+
+	// fetch one extra row to know whether there is a next page
+	limit := filter.Limit
+	filter.Limit++
+	events, err := s.storage.ListAuditEvents(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var nextPageToken string
+	if len(events) > limit {
+		events = events[:limit]
+		last := events[limit-1]
+		nextPageToken = encodeMessageCursor(&storage.MessageCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	result := make([]*pb.AuditEvent, 0, len(events))
+	for _, e := range events {
+		result = append(result, toProtoAuditEvent(e))
+	}
+	return result, nextPageToken, nil
+}
+
+func toProtoAuditEvent(e *storage.AuditEvent) *pb.AuditEvent {
+	changes := make([]*pb.FieldChange, 0, len(e.Diff))
+	for field, change := range e.Diff {
+		changes = append(changes, &pb.FieldChange{
+			Field:  field,
+			Before: string(change.Before),
+			After:  string(change.After),
+		})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+
+	return &pb.AuditEvent{
+		Id:          e.ID,
+		Actor:       e.Actor,
+		PeerAddress: e.PeerAddress,
+		Method:      e.Method,
+		Action:      string(e.Action),
+		ConnectorId: e.ConnectorID,
+		TenantId:    e.WorkspaceID,
+		Changes:     changes,
+		CreatedAt:   timestamppb.New(e.CreatedAt),
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"connector-recruitment/go-server/connectors/errs"
+	"connector-recruitment/go-server/connectors/integrations/slack"
+	"connector-recruitment/go-server/connectors/storage"
+)
+
+const (
+	// breakerThreshold is the number of consecutive transient failures that opens a
+	// connector's circuit.
+	breakerThreshold = 5
+	// breakerCooldown is how long an open circuit rejects sends before letting one through.
+	breakerCooldown = time.Minute
+)
+
+// circuitBreaker tracks consecutive delivery failures per connector. Transient failures
+// open the circuit for cooldown once they reach threshold; permanent failures suspend the
+// connector in storage, see ConnectorService.checkDelivery.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time // replaced by tests
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	failures  int
+	openUntil time.Time
+}
+
+func newCircuitBreaker() *circuitBreaker {
+	return &circuitBreaker{
+		threshold: breakerThreshold,
+		cooldown:  breakerCooldown,
+		now:       time.Now,
+		circuits:  make(map[string]*circuit),
+	}
+}
+
+// allow returns ErrCircuitOpen while the connector's circuit is open. Once the cooldown
+// elapsed a single send is let through: its failure opens the circuit again.
+func (b *circuitBreaker) allow(connectorID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[connectorID]
+	if !ok || c.failures < b.threshold {
+		return nil
+	}
+	now := b.now()
+	if now.Before(c.openUntil) {
+		return fmt.Errorf("%w: connector %s failed %d times in a row", errs.ErrCircuitOpen, connectorID, c.failures)
+	}
+	c.openUntil = now.Add(b.cooldown)
+	return nil
+}
+
+func (b *circuitBreaker) success(connectorID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.circuits, connectorID)
+}
+
+// forget drops the circuit of a deleted connector.
+func (b *circuitBreaker) forget(connectorID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.circuits, connectorID)
+}
+
+func (b *circuitBreaker) failure(connectorID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[connectorID]
+	if !ok {
+		c = &circuit{}
+		b.circuits[connectorID] = c
+	}
+	c.failures++
+	if c.failures >= b.threshold {
+		c.openUntil = b.now().Add(b.cooldown)
+	}
+}
+
+// checkSendable fails fast when the connector is suspended or its circuit is open.
+func (s *ConnectorService) checkSendable(connector *storage.Connector) error {
+	if connector.Status == storage.ConnectorStatusSuspended {
+		return errs.NewConnectorSuspendedError(connector.ID, connector.StatusReason)
+	}
+	return s.breaker.allow(connector.ID)
+}
+
+// checkDelivery feeds the outcome of a delivery to the circuit breaker. A permanent slack
+// failure suspends the connector and is reported as ErrConnectorSuspended. A missing
+// channel only suspends the connector when it is its default channel: a routing rule
+// pointing to another channel must not take the whole connector down.
+func (s *ConnectorService) checkDelivery(ctx context.Context, connector *storage.Connector, channelID string, err error) error {
+	if err == nil {
+		s.breaker.success(connector.ID)
+		return nil
+	}
+
+	var slackErr *slack.Error
+	permanent := errors.As(err, &slackErr) && slackErr.Permanent() &&
+		!(slackErr.Code == slack.ErrCodeChannelNotFound && channelID != "" && channelID != connector.DefaultChannelID)
+	if !permanent {
+		s.breaker.failure(connector.ID)
+		return err
+	}
+
+	// the suspension must stick even when the caller gives up on the request
+	ctx = context.WithoutCancel(ctx)
+	if statusErr := s.storage.SetConnectorStatus(ctx, connector.ID, storage.ConnectorStatusSuspended, slackErr.Code); statusErr != nil {
+		s.logger.Error("failed to suspend connector", "connector-id", connector.ID, "err", statusErr)
+		s.breaker.failure(connector.ID)
+		return err
+	}
+	s.logger.Warn("connector suspended", "connector-id", connector.ID, "reason", slackErr.Code)
+	return errs.NewConnectorSuspendedError(connector.ID, slackErr.Code)
+}
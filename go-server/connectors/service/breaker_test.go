@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"connector-recruitment/go-server/connectors/errs"
+	"connector-recruitment/go-server/connectors/integrations/slack"
+	"connector-recruitment/go-server/connectors/storage"
+)
+
+// fakeClock is a clock that only moves when told to.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func TestCircuitBreaker(t *testing.T) {
+	// steps: "fail" and "ok" report a delivery, "wait <duration>" moves the clock, and
+	// "allow" and "deny" check whether the next send is let through.
+	tests := []struct {
+		name  string
+		steps string
+	}{
+		{"closed", "allow fail allow"},
+		{"below the threshold", "fail fail fail fail allow"},
+		{"threshold opens", "fail fail fail fail fail deny"},
+		{"open during the cooldown", "fail fail fail fail fail wait 59s deny"},
+		{"half open after the cooldown", "fail fail fail fail fail wait 1m allow deny"},
+		{"failed probe opens again", "fail fail fail fail fail wait 1m allow fail wait 59s deny wait 1s allow"},
+		{"successful probe closes", "fail fail fail fail fail wait 1m allow ok allow allow"},
+		{"success resets the count", "fail fail fail fail ok fail fail fail fail allow"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+			b := newCircuitBreaker()
+			b.now = clock.now
+
+			steps := strings.Fields(tt.steps)
+			for i := 0; i < len(steps); i++ {
+				switch steps[i] {
+				case "fail":
+					b.failure("c1")
+				case "ok":
+					b.success("c1")
+				case "wait":
+					i++
+					d, err := time.ParseDuration(steps[i])
+					if err != nil {
+						t.Fatal(err)
+					}
+					clock.advance(d)
+				case "allow", "deny":
+					err := b.allow("c1")
+					if steps[i] == "allow" && err != nil {
+						t.Fatalf("step %d: allow = %v, want the send let through", i, err)
+					}
+					if steps[i] == "deny" && !errors.Is(err, errs.ErrCircuitOpen) {
+						t.Fatalf("step %d: allow = %v, want %v", i, err, errs.ErrCircuitOpen)
+					}
+				default:
+					t.Fatalf("unknown step %q", steps[i])
+				}
+			}
+			if err := b.allow("c2"); err != nil {
+				t.Errorf("the circuit of another connector is open: %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckDelivery(t *testing.T) {
+	tests := []struct {
+		name          string
+		channel       string // empty for the default channel
+		code          string
+		wantSuspended bool
+	}{
+		{"revoked token", "", slack.ErrCodeTokenRevoked, true},
+		{"invalid auth", "", slack.ErrCodeInvalidAuth, true},
+		{"default channel not found", "", slack.ErrCodeChannelNotFound, true},
+		{"default channel named", "C-default", slack.ErrCodeChannelNotFound, true},
+		{"routed channel not found", "C-routed", slack.ErrCodeChannelNotFound, false},
+		{"revoked token on a routed channel", "C-routed", slack.ErrCodeTokenRevoked, true},
+		{"transient error", "", "ratelimited", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, fake, tenant := newTestService(t)
+			ctx := context.Background()
+			connector := saveConnector(t, s, &storage.Connector{
+				WorkspaceID:      tenant.ID,
+				DefaultChannelID: "C-default",
+				Type:             storage.ConnectorTypeSlack,
+			})
+			channel := tt.channel
+			if channel == "" {
+				channel = connector.DefaultChannelID
+			}
+			fake.fail(channel, tt.code)
+
+			_, err := s.deliver(ctx, connector, tt.channel, outgoing{text: "hello"})
+			if suspended := errors.Is(err, errs.ErrConnectorSuspended); suspended != tt.wantSuspended {
+				t.Errorf("deliver = %v, want suspended %v", err, tt.wantSuspended)
+			}
+			stored, err := s.storage.GetConnectorByID(ctx, connector.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantSuspended {
+				if stored.Status != storage.ConnectorStatusSuspended || stored.StatusReason != tt.code {
+					t.Errorf("connector is %s (%q), want suspended for %q", stored.Status, stored.StatusReason, tt.code)
+				}
+				return
+			}
+			if stored.Status != storage.ConnectorStatusActive {
+				t.Errorf("connector is %s (%q), want it active", stored.Status, stored.StatusReason)
+			}
+			if got := s.breaker.circuits[connector.ID]; got == nil || got.failures != 1 {
+				t.Errorf("the failure was not counted by the circuit breaker: %+v", got)
+			}
+		})
+	}
+}
+
+func TestDeliverOpensCircuit(t *testing.T) {
+	s, fake, tenant := newTestService(t)
+	clock := &fakeClock{t: time.Now()}
+	s.breaker.now = clock.now
+	ctx := context.Background()
+	connector := saveConnector(t, s, &storage.Connector{
+		WorkspaceID:      tenant.ID,
+		DefaultChannelID: "C-default",
+		Type:             storage.ConnectorTypeSlack,
+	})
+	fake.fail("C-default", "ratelimited")
+
+	for range breakerThreshold {
+		if _, err := s.deliver(ctx, connector, "", outgoing{text: "hello"}); err == nil || errors.Is(err, errs.ErrCircuitOpen) {
+			t.Fatalf("deliver = %v, want the slack error", err)
+		}
+	}
+	if _, err := s.deliver(ctx, connector, "", outgoing{text: "hello"}); !errors.Is(err, errs.ErrCircuitOpen) {
+		t.Fatalf("deliver after %d failures = %v, want %v", breakerThreshold, err, errs.ErrCircuitOpen)
+	}
+	if n := len(fake.messages()); n != breakerThreshold {
+		t.Errorf("slack got %d posts, want %d: an open circuit must not post", n, breakerThreshold)
+	}
+
+	fake.fail("C-default", "")
+	clock.advance(breakerCooldown)
+	if _, err := s.deliver(ctx, connector, "", outgoing{text: "hello"}); err != nil {
+		t.Fatalf("deliver after the cooldown = %v, want it sent", err)
+	}
+	if _, err := s.deliver(ctx, connector, "", outgoing{text: "hello"}); err != nil {
+		t.Fatalf("deliver after a successful probe = %v, want it sent", err)
+	}
+}
@@ -2,53 +2,104 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	pb "connector-recruitment/go-server/connectors/genproto"
+	"connector-recruitment/go-server/connectors/integrations/email"
 	"connector-recruitment/go-server/connectors/integrations/slack"
+	"connector-recruitment/go-server/connectors/integrations/slack/mrkdwn"
+	"connector-recruitment/go-server/connectors/integrations/webhook"
 	"connector-recruitment/go-server/connectors/logger"
 	"connector-recruitment/go-server/connectors/storage"
 
-	"github.com/aws/aws-sdk-go/service/secretsmanager"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// broadcastConcurrency bounds the number of deliveries a single broadcast or routed
+// message runs in parallel.
+const broadcastConcurrency = 8
+
 type ConnectorService struct {
-	logger   logger.Logger
-	storage  storage.Storage
-	smClient *secretsmanager.SecretsManager
+	logger  logger.Logger
+	storage storage.Storage
+	digests *digester
+	breaker *circuitBreaker
+	// slackURL is the base URL of the Slack Web API, replaced by tests.
+	slackURL string
 }
 
-func NewConnectorService(storage storage.Storage, smClient *secretsmanager.SecretsManager, logger logger.Logger) *ConnectorService {
-	return &ConnectorService{
-		logger:   logger,
-		storage:  storage,
-		smClient: smClient,
+func NewConnectorService(storage storage.Storage, logger logger.Logger) *ConnectorService {
+	s := &ConnectorService{
+		logger:  logger,
+		storage: storage,
+		breaker: newCircuitBreaker(),
+
+		slackURL: slack.BaseUrl,
 	}
+	s.digests = newDigester(s.flushDigest, logger)
+	return s
+}
+
+// Close sends the digests still being buffered. Call it once the server stopped accepting requests.
+func (s *ConnectorService) Close() {
+	s.digests.close()
 }
 
-func (s *ConnectorService) GetConnector(ctx context.Context, ID string) (*pb.Connector, error) {
+// GetConnector returns a connector, from the read replica unless readYourWrites.
+func (s *ConnectorService) GetConnector(ctx context.Context, ID string, readYourWrites bool) (*pb.Connector, error) {
+	if !readYourWrites {
+		ctx = storage.WithReplicaReads(ctx)
+	}
 	connectorRow, err := s.storage.GetConnectorByID(ctx, ID)
 	if err != nil {
 		return nil, err
 	}
 
-	return &pb.Connector{
-		Id:               connectorRow.ID,
-		TenantId:         connectorRow.WorkspaceID,
-		DefaultChannelId: connectorRow.DefaultChannelID,
-		CreatedAt:        timestamppb.New(connectorRow.CreatedAt),
-		UpdatedAt:        timestamppb.New(connectorRow.UpdatedAt),
-	}, nil
+	return toProtoConnector(connectorRow), nil
 }
 
-func (s *ConnectorService) CreateConnector(ctx context.Context, slackToken string, connector *pb.Connector) error {
-	_, err := s.storage.SaveConnector(ctx, &storage.Connector{
+// CreateConnector stores the connector metadata and its secret. The secret is the slack
+// token for slack connectors, the signing secret for webhook connectors and the SMTP
+// password for email connectors.
+func (s *ConnectorService) CreateConnector(ctx context.Context, secret string, connector *pb.Connector) error {
+	if err := s.checkTenant(ctx, connector.TenantId); err != nil {
+		return err
+	}
+
+	row := &storage.Connector{
 		WorkspaceID:      connector.TenantId,
 		DefaultChannelID: connector.DefaultChannelId,
-		Token:            slackToken,
-	})
+		Type:             fromProtoConnectorType(connector.Type),
+		Token:            secret,
+	}
+	if wh := connector.GetWebhook(); wh != nil {
+		row.Settings.Webhook = &storage.WebhookSettings{
+			URL:            wh.Url,
+			Headers:        wh.Headers,
+			TimeoutSeconds: int(wh.TimeoutSeconds),
+			MaxRetries:     int(wh.MaxRetries),
+		}
+	}
+
+	if em := connector.GetEmail(); em != nil {
+		row.Settings.Email = &storage.EmailSettings{
+			SMTPHost: em.SmtpHost,
+			SMTPPort: int(em.SmtpPort),
+			Username: em.Username,
+			From:     em.From,
+			To:       em.To,
+		}
+	}
+
+	if dg := connector.GetDigest(); dg != nil && dg.WindowSeconds > 0 {
+		row.Settings.Digest = &storage.DigestSettings{WindowSeconds: int(dg.WindowSeconds)}
+	}
+
+	_, err := s.storage.SaveConnector(ctx, row)
 	if err != nil {
 		return err
 	}
@@ -56,7 +107,11 @@ func (s *ConnectorService) CreateConnector(ctx context.Context, slackToken strin
 	return nil
 }
 
-func (s *ConnectorService) GetConnectors(ctx context.Context) []*pb.Connector {
+// GetConnectors returns every connector, from the read replica unless readYourWrites.
+func (s *ConnectorService) GetConnectors(ctx context.Context, readYourWrites bool) []*pb.Connector {
+	if !readYourWrites {
+		ctx = storage.WithReplicaReads(ctx)
+	}
 	conns, err := s.storage.GetAllConnectors(ctx)
 	if err != nil {
 		return nil
@@ -65,42 +120,365 @@ func (s *ConnectorService) GetConnectors(ctx context.Context) []*pb.Connector {
 	var result []*pb.Connector
 
 	for _, conn := range conns {
-		pbConnector := &pb.Connector{
-			Id:               conn.ID,
-			TenantId:         conn.WorkspaceID,
-			DefaultChannelId: conn.DefaultChannelID,
-			CreatedAt:        timestamppb.New(conn.CreatedAt),
-			UpdatedAt:        timestamppb.New(conn.UpdatedAt),
-		}
-
-		result = append(result, pbConnector)
+		result = append(result, toProtoConnector(conn))
 	}
 
 	return result
 }
 
-func (s *ConnectorService) DeleteConnector(ctx context.Context, ID string) error {
-	err := s.storage.DeleteConnector(ctx, ID)
+// DeleteConnector deletes a connector and its secret. A non-empty etag must be the
+// connector's current etag.
+func (s *ConnectorService) DeleteConnector(ctx context.Context, ID, etag string) error {
+	version, err := parseEtag(etag)
 	if err != nil {
 		return err
 	}
+	err = s.storage.DeleteConnector(ctx, ID, version)
+	if err != nil {
+		return err
+	}
+	s.breaker.forget(ID)
 	return nil
 }
 
-func (s *ConnectorService) SendMessage(ctx context.Context, connectorID string, message string) error {
-	connectorActual, err := s.storage.GetConnectorByID(ctx, connectorID)
+func (s *ConnectorService) SendMessage(ctx context.Context, req *pb.SendMessageRequest) (*pb.Delivery, error) {
+	connectorActual, err := s.storage.GetConnectorByID(ctx, req.ConnectorId)
 	if err != nil {
+		return nil, err
+	}
+	if err := s.checkSendable(connectorActual); err != nil {
+		return nil, err
+	}
+
+	if digest := connectorActual.Settings.Digest; digest != nil && digest.WindowSeconds > 0 &&
+		req.Priority != pb.MessagePriority_MESSAGE_PRIORITY_URGENT {
+		key := req.DedupeKey
+		if key == "" {
+			key = contentHash(req.Message)
+		}
+		s.digests.add(connectorActual.ID, time.Duration(digest.WindowSeconds)*time.Second, key, req.Message)
+		return &pb.Delivery{
+			ConnectorId: connectorActual.ID,
+			Type:        toProtoConnectorType(connectorActual.Type),
+			Queued:      true,
+		}, nil
+	}
+
+	return s.deliver(ctx, connectorActual, "", outgoing{text: req.Message, format: req.Format})
+}
+
+// outgoing is a message on its way to a connector.
+type outgoing struct {
+	text   string
+	format pb.MessageFormat
+}
+
+// BroadcastMessage sends the message through every connector of the tenant, or through the
+// given connectors when connectorIDs is not empty. A failing connector does not stop the
+// others; results are returned in the order of the targeted connectors.
+func (s *ConnectorService) BroadcastMessage(ctx context.Context, tenantID string, connectorIDs []string, message string) ([]*pb.BroadcastResult, error) {
+	var jobs []deliveryJob
+
+	if len(connectorIDs) > 0 {
+		seen := make(map[string]bool, len(connectorIDs))
+		for _, id := range connectorIDs {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			jobs = append(jobs, deliveryJob{connectorID: id})
+		}
+	} else {
+		conns, err := s.storage.GetConnectorsByWorkspaceID(ctx, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range conns {
+			jobs = append(jobs, deliveryJob{connectorID: c.ID, connector: c})
+		}
+	}
+
+	return s.fanOut(ctx, jobs, outgoing{text: message}), nil
+}
+
+// deliveryJob is a single delivery of a fan-out. A nil connector is loaded by ID.
+type deliveryJob struct {
+	connectorID string
+	connector   *storage.Connector
+	channelID   string
+}
+
+// fanOut runs the deliveries concurrently, at most broadcastConcurrency at a time, and
+// returns one result per job in the order of jobs. The jobs not started when ctx is done
+// fail with its error.
+func (s *ConnectorService) fanOut(ctx context.Context, jobs []deliveryJob, msg outgoing) []*pb.BroadcastResult {
+	results := make([]*pb.BroadcastResult, len(jobs))
+	sem := make(chan struct{}, broadcastConcurrency)
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		results[i] = &pb.BroadcastResult{ConnectorId: job.connectorID}
+
+		if err := acquire(ctx, sem); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		wg.Add(1)
+		go func(i int, job deliveryJob) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			connector := job.connector
+			if connector == nil {
+				var err error
+				connector, err = s.storage.GetConnectorByID(ctx, job.connectorID)
+				if err != nil {
+					results[i].Error = err.Error()
+					return
+				}
+			}
+
+			delivery, err := s.deliver(ctx, connector, job.channelID, msg)
+			results[i].Delivery = delivery
+			if err != nil {
+				s.logger.Warn("fan-out delivery failed", "connector-id", job.connectorID, "err", err)
+				results[i].Error = err.Error()
+			}
+		}(i, job)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// acquire takes a slot of sem, unless ctx is done first.
+func acquire(ctx context.Context, sem chan struct{}) error {
+	if err := ctx.Err(); err != nil {
 		return err
 	}
+	select {
+	case sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// deliver sends the message through the integration matching the connector's type and
+// records the attempt in the message history. channelID overrides the default channel of
+// slack connectors when set. Suspended connectors and open circuits fail without sending.
+// When a split slack message fails after some of its parts were posted, the delivery of
+// those parts is returned along with the error.
+func (s *ConnectorService) deliver(ctx context.Context, connector *storage.Connector, channelID string, msg outgoing) (*pb.Delivery, error) {
+	if err := s.checkSendable(connector); err != nil {
+		return nil, err
+	}
+
+	var (
+		delivery *pb.Delivery
+		err      error
+	)
+	start := time.Now()
+	token, err := s.storage.GetConnectorToken(ctx, connector)
+	if err == nil {
+		switch connector.Type {
+		case storage.ConnectorTypeWebhook:
+			delivery, err = s.sendWebhook(ctx, connector, token, msg.text)
+		case storage.ConnectorTypeEmail:
+			delivery, err = s.sendEmail(ctx, connector, token, msg.text)
+		case storage.ConnectorTypeSlack, "":
+			if channelID == "" {
+				channelID = connector.DefaultChannelID
+			}
+			delivery, err = s.sendSlack(ctx, connector, token, channelID, msg)
+		default:
+			return nil, fmt.Errorf("unsupported connector type %q", connector.Type)
+		}
+	}
+
+	s.recordDelivery(ctx, connector, delivery, msg.text, time.Since(start), err)
+	if err := s.checkDelivery(ctx, connector, channelID, err); err != nil {
+		if delivery != nil && delivery.Ts != "" {
+			return delivery, err
+		}
+		return nil, err
+	}
+	return delivery, nil
+}
+
+func (s *ConnectorService) sendSlack(ctx context.Context, connector *storage.Connector, token, channelID string, msg outgoing) (*pb.Delivery, error) {
+	slackClient := s.newSlackClient()
+
+	delivery := &pb.Delivery{
+		ConnectorId: connector.ID,
+		Type:        pb.ConnectorType_CONNECTOR_TYPE_SLACK,
+		Channel:     channelID,
+	}
+	responses, err := slackClient.PostSplitMessage(ctx, token, slackMessage(channelID, msg))
+	for i, resp := range responses {
+		if i == 0 {
+			delivery.Channel = resp.Channel
+			delivery.Ts = resp.TS
+			continue
+		}
+		delivery.ReplyTs = append(delivery.ReplyTs, resp.TS)
+	}
+	return delivery, err
+}
 
-	slackClient := slack.NewClient(slack.BaseUrl, &http.Client{
+func (s *ConnectorService) newSlackClient() *slack.Client {
+	return slack.NewClient(s.slackURL, &http.Client{
 		Timeout: 10 * time.Second,
 	}, s.logger)
+}
 
-	if err := slackClient.SendMessageToChannel(ctx, connectorActual.Token, connectorActual.DefaultChannelID, message); err != nil {
-		return err
+// slackMessage renders the message text according to its format.
+func slackMessage(channelID string, msg outgoing) slack.Message {
+	switch msg.format {
+	case pb.MessageFormat_MESSAGE_FORMAT_PLAIN:
+		mrkdwnEnabled := false
+		return slack.Message{Channel: channelID, Text: mrkdwn.Escape(msg.text), Mrkdwn: &mrkdwnEnabled}
+	case pb.MessageFormat_MESSAGE_FORMAT_MARKDOWN:
+		return slack.Message{
+			Channel: channelID,
+			Text:    mrkdwn.FromMarkdown(msg.text),
+			Blocks:  mrkdwn.BlocksFromMarkdown(msg.text),
+		}
+	default:
+		return slack.Message{Channel: channelID, Text: msg.text}
 	}
+}
 
-	// slog.Info("sending message to slack", "msg", message, "slack-channel", connectorActual.DefaultChannelID)
-	return nil
+// sendWebhook, sendEmail and sendSlack return the attempted delivery even when they fail,
+// so that failed attempts are recorded with what is known about them.
+func (s *ConnectorService) sendWebhook(ctx context.Context, connector *storage.Connector, secret, message string) (*pb.Delivery, error) {
+	delivery := &pb.Delivery{
+		ConnectorId: connector.ID,
+		Type:        pb.ConnectorType_CONNECTOR_TYPE_WEBHOOK,
+	}
+	settings := connector.Settings.Webhook
+	if settings == nil {
+		return delivery, fmt.Errorf("webhook connector %s has no webhook settings", connector.ID)
+	}
+
+	timeout := webhook.DefaultTimeout
+	if settings.TimeoutSeconds > 0 {
+		timeout = time.Duration(settings.TimeoutSeconds) * time.Second
+	}
+	webhookClient := webhook.NewClient(&http.Client{Timeout: timeout}, s.logger)
+
+	statusCode, err := webhookClient.Send(ctx, webhook.Request{
+		URL:        settings.URL,
+		Secret:     secret,
+		Headers:    settings.Headers,
+		MaxRetries: settings.MaxRetries,
+		Envelope: webhook.Envelope{
+			ConnectorID: connector.ID,
+			TenantID:    connector.WorkspaceID,
+			Message:     message,
+			Timestamp:   time.Now().UTC(),
+		},
+	})
+	s.logger.Info("webhook delivery outcome", "connector-id", connector.ID, "status", statusCode, "err", err)
+	delivery.StatusCode = int32(statusCode)
+	return delivery, err
+}
+
+func (s *ConnectorService) sendEmail(ctx context.Context, connector *storage.Connector, password, message string) (*pb.Delivery, error) {
+	delivery := &pb.Delivery{
+		ConnectorId: connector.ID,
+		Type:        pb.ConnectorType_CONNECTOR_TYPE_EMAIL,
+	}
+	settings := connector.Settings.Email
+	if settings == nil {
+		return delivery, fmt.Errorf("email connector %s has no email settings", connector.ID)
+	}
+
+	emailClient := email.NewClient(nil, email.DefaultTimeout, s.logger)
+	err := emailClient.Send(ctx, email.Server{
+		Host:     settings.SMTPHost,
+		Port:     settings.SMTPPort,
+		Username: settings.Username,
+		Password: password,
+	}, email.Message{
+		From:    settings.From,
+		To:      settings.To,
+		Subject: emailSubject(message),
+		Body:    message,
+	})
+	return delivery, err
+}
+
+// emailSubject uses the first line of the message, shortened to fit a subject line.
+func emailSubject(message string) string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	subject = strings.TrimSpace(subject)
+	if r := []rune(subject); len(r) > 78 {
+		subject = string(r[:75]) + "..."
+	}
+	return subject
+}
+
+func toProtoConnector(c *storage.Connector) *pb.Connector {
+	pbConnector := &pb.Connector{
+		Id:               c.ID,
+		TenantId:         c.WorkspaceID,
+		DefaultChannelId: c.DefaultChannelID,
+		Type:             toProtoConnectorType(c.Type),
+		Status:           toProtoConnectorStatus(c.Status),
+		StatusReason:     c.StatusReason,
+		Health:           toProtoConnectorHealth(c.Health),
+		Etag:             formatEtag(c.Version),
+		CreatedAt:        timestamppb.New(c.CreatedAt),
+		UpdatedAt:        timestamppb.New(c.UpdatedAt),
+	}
+	if wh := c.Settings.Webhook; wh != nil {
+		pbConnector.Webhook = &pb.WebhookConfig{
+			Url:            wh.URL,
+			Headers:        wh.Headers,
+			TimeoutSeconds: int32(wh.TimeoutSeconds),
+			MaxRetries:     int32(wh.MaxRetries),
+		}
+	}
+	if em := c.Settings.Email; em != nil {
+		pbConnector.Email = &pb.EmailConfig{
+			SmtpHost: em.SMTPHost,
+			SmtpPort: int32(em.SMTPPort),
+			Username: em.Username,
+			From:     em.From,
+			To:       em.To,
+		}
+	}
+	if dg := c.Settings.Digest; dg != nil {
+		pbConnector.Digest = &pb.DigestConfig{WindowSeconds: int32(dg.WindowSeconds)}
+	}
+	return pbConnector
+}
+
+func toProtoConnectorType(t storage.ConnectorType) pb.ConnectorType {
+	switch t {
+	case storage.ConnectorTypeWebhook:
+		return pb.ConnectorType_CONNECTOR_TYPE_WEBHOOK
+	case storage.ConnectorTypeEmail:
+		return pb.ConnectorType_CONNECTOR_TYPE_EMAIL
+	default:
+		return pb.ConnectorType_CONNECTOR_TYPE_SLACK
+	}
+}
+
+func toProtoConnectorStatus(s storage.ConnectorStatus) pb.ConnectorStatus {
+	if s == storage.ConnectorStatusSuspended {
+		return pb.ConnectorStatus_CONNECTOR_STATUS_SUSPENDED
+	}
+	return pb.ConnectorStatus_CONNECTOR_STATUS_ACTIVE
+}
+
+func fromProtoConnectorType(t pb.ConnectorType) storage.ConnectorType {
+	switch t {
+	case pb.ConnectorType_CONNECTOR_TYPE_WEBHOOK:
+		return storage.ConnectorTypeWebhook
+	case pb.ConnectorType_CONNECTOR_TYPE_EMAIL:
+		return storage.ConnectorTypeEmail
+	default:
+		return storage.ConnectorTypeSlack
+	}
 }
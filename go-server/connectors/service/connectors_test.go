@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"connector-recruitment/go-server/connectors/storage"
+)
+
+func TestFanOutBoundsDeliveries(t *testing.T) {
+	s, _, tenant := newTestService(t)
+
+	var (
+		mu       sync.Mutex
+		inFlight int
+		maxSeen  int
+		posted   int
+	)
+	started := make(chan struct{}, 100)
+	release := make(chan struct{})
+	blocking := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		posted++
+		maxSeen = max(maxSeen, inFlight)
+		mu.Unlock()
+		started <- struct{}{}
+
+		<-release
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		fmt.Fprint(w, `{"ok":true,"channel":"C0","ts":"1700000000.000100"}`)
+	}))
+	defer blocking.Close()
+	s.slackURL = blocking.URL
+
+	var jobs []deliveryJob
+	for i := range 3 * broadcastConcurrency {
+		c := saveConnector(t, s, &storage.Connector{
+			WorkspaceID:      tenant.ID,
+			DefaultChannelID: fmt.Sprintf("C%d", i),
+			Type:             storage.ConnectorTypeSlack,
+		})
+		jobs = append(jobs, deliveryJob{connectorID: c.ID, connector: c})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan []string)
+	go func() {
+		var errors []string
+		for _, r := range s.fanOut(ctx, jobs, outgoing{text: "hello"}) {
+			errors = append(errors, r.Error)
+		}
+		done <- errors
+	}()
+
+	// the first deliveries hold every slot: cancelling now must not start the others
+	for range broadcastConcurrency {
+		<-started
+	}
+	cancel()
+	close(release)
+	errors := <-done
+
+	if maxSeen > broadcastConcurrency {
+		t.Errorf("%d deliveries ran at once, want at most %d", maxSeen, broadcastConcurrency)
+	}
+	if posted != broadcastConcurrency {
+		t.Errorf("%d deliveries were posted, want the %d started before the cancellation", posted, broadcastConcurrency)
+	}
+	for i, err := range errors[broadcastConcurrency:] {
+		if err != context.Canceled.Error() {
+			t.Errorf("job %d not started before the cancellation failed with %q, want %q", broadcastConcurrency+i, err, context.Canceled)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	pb "connector-recruitment/go-server/connectors/genproto"
+	"connector-recruitment/go-server/connectors/integrations/slack/mrkdwn"
+	"connector-recruitment/go-server/connectors/logger"
+)
+
+const (
+	// maxDigestGroups bounds the distinct messages a digest keeps; later distinct
+	// messages are only counted.
+	maxDigestGroups = 100
+	// maxDigestLineLength bounds how much of each grouped message the summary shows.
+	maxDigestLineLength = 300
+)
+
+// digestGroup is the set of buffered messages sharing a dedupe key.
+type digestGroup struct {
+	text  string
+	count int
+}
+
+// digestBuffer holds the messages of one connector until its window closes.
+type digestBuffer struct {
+	window   time.Duration
+	started  time.Time
+	groups   map[string]*digestGroup
+	order    []string
+	overflow int
+	timer    stopper
+}
+
+// stopper is the timer closing a digest window.
+type stopper interface {
+	Stop() bool
+}
+
+// digester buffers messages per connector and hands each closed window to flush.
+type digester struct {
+	mu      sync.Mutex
+	buffers map[string]*digestBuffer
+	flush   func(connectorID string, summary string)
+	logger  logger.Logger
+
+	// now and afterFunc are replaced by tests.
+	now       func() time.Time
+	afterFunc func(d time.Duration, f func()) stopper
+}
+
+func newDigester(flush func(connectorID, summary string), logger logger.Logger) *digester {
+	return &digester{
+		buffers:   make(map[string]*digestBuffer),
+		flush:     flush,
+		logger:    logger,
+		now:       time.Now,
+		afterFunc: func(d time.Duration, f func()) stopper { return time.AfterFunc(d, f) },
+	}
+}
+
+// add buffers a message; the first message of a connector opens a window that is
+// flushed when it elapses.
+func (d *digester) add(connectorID string, window time.Duration, key, text string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	buf, ok := d.buffers[connectorID]
+	if !ok {
+		buf = &digestBuffer{
+			window:  window,
+			started: d.now(),
+			groups:  make(map[string]*digestGroup),
+		}
+		buf.timer = d.afterFunc(window, func() { d.flushConnector(connectorID) })
+		d.buffers[connectorID] = buf
+	}
+
+	if g, ok := buf.groups[key]; ok {
+		g.count++
+		return
+	}
+	if len(buf.groups) >= maxDigestGroups {
+		buf.overflow++
+		return
+	}
+	buf.groups[key] = &digestGroup{text: text, count: 1}
+	buf.order = append(buf.order, key)
+}
+
+func (d *digester) flushConnector(connectorID string) {
+	d.mu.Lock()
+	buf, ok := d.buffers[connectorID]
+	delete(d.buffers, connectorID)
+	d.mu.Unlock()
+
+	if ok {
+		d.flush(connectorID, buf.summary(d.now()))
+	}
+}
+
+// close flushes every open window immediately, so that no buffered message is lost on shutdown.
+func (d *digester) close() {
+	d.mu.Lock()
+	ids := make([]string, 0, len(d.buffers))
+	for id, buf := range d.buffers {
+		buf.timer.Stop()
+		ids = append(ids, id)
+	}
+	d.mu.Unlock()
+
+	for _, id := range ids {
+		d.flushConnector(id)
+	}
+}
+
+// summary renders the buffered messages as one mrkdwn message, in order of first arrival,
+// when the window closes at now. The messages are escaped: a digest must not ping anyone.
+func (b *digestBuffer) summary(now time.Time) string {
+	total := b.overflow
+	for _, g := range b.groups {
+		total += g.count
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "*Digest:* %d messages in the last %s", total, now.Sub(b.started).Round(time.Second))
+	for _, key := range b.order {
+		g := b.groups[key]
+		fmt.Fprintf(&sb, "\n• ×%d %s", g.count, mrkdwn.Escape(digestLine(g.text)))
+	}
+	if b.overflow > 0 {
+		fmt.Fprintf(&sb, "\n• …and %d more messages", b.overflow)
+	}
+	return sb.String()
+}
+
+// digestLine is the first line of a message, shortened for the summary.
+func digestLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	if r := []rune(line); len(r) > maxDigestLineLength {
+		line = string(r[:maxDigestLineLength-1]) + "…"
+	}
+	return line
+}
+
+// flushDigest sends a closed digest window through the connector. It runs outside any
+// request, so it loads the connector again and uses a context of its own.
+func (s *ConnectorService) flushDigest(connectorID, summary string) {
+	ctx, cancel := context.WithTimeout(context.Background(), digestFlushTimeout)
+	defer cancel()
+
+	connector, err := s.storage.GetConnectorByID(ctx, connectorID)
+	if err != nil {
+		s.logger.Error("failed to load connector for digest", "connector-id", connectorID, "err", err)
+		return
+	}
+	if _, err := s.deliver(ctx, connector, "", outgoing{text: summary, format: pb.MessageFormat_MESSAGE_FORMAT_MRKDWN}); err != nil {
+		s.logger.Error("failed to send digest", "connector-id", connectorID, "err", err)
+	}
+}
+
+// digestFlushTimeout bounds the delivery of a single digest.
+const digestFlushTimeout = 30 * time.Second
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeTimers records the windows opened by a digester, to be fired by the test.
+type fakeTimers struct {
+	mu     sync.Mutex
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	d       time.Duration
+	f       func()
+	stopped bool
+}
+
+func (t *fakeTimer) Stop() bool {
+	wasActive := !t.stopped
+	t.stopped = true
+	return wasActive
+}
+
+func (ft *fakeTimers) afterFunc(d time.Duration, f func()) stopper {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	t := &fakeTimer{d: d, f: f}
+	ft.timers = append(ft.timers, t)
+	return t
+}
+
+// fire runs the function of the i-th timer, as its window elapsed.
+func (ft *fakeTimers) fire(i int) {
+	ft.mu.Lock()
+	t := ft.timers[i]
+	ft.mu.Unlock()
+	t.f()
+}
+
+// flushes records the summaries flushed by a digester.
+type flushes struct {
+	mu        sync.Mutex
+	summaries map[string][]string
+}
+
+func (f *flushes) flush(connectorID, summary string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.summaries[connectorID] = append(f.summaries[connectorID], summary)
+}
+
+func (f *flushes) of(connectorID string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.summaries[connectorID]
+}
+
+func newTestDigester() (*digester, *fakeTimers, *fakeClock, *flushes) {
+	timers := &fakeTimers{}
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	flushed := &flushes{summaries: make(map[string][]string)}
+	d := newDigester(flushed.flush, discard)
+	d.now = clock.now
+	d.afterFunc = timers.afterFunc
+	return d, timers, clock, flushed
+}
+
+func TestDigesterWindow(t *testing.T) {
+	d, timers, clock, flushed := newTestDigester()
+
+	d.add("c1", time.Minute, "disk", "disk full on db-1\nmore details")
+	d.add("c1", time.Minute, "cpu", "cpu high")
+	d.add("c1", time.Minute, "disk", "disk full on db-1")
+	if len(timers.timers) != 1 || timers.timers[0].d != time.Minute {
+		t.Fatalf("the messages opened %d windows, want one of a minute", len(timers.timers))
+	}
+	if got := flushed.of("c1"); len(got) != 0 {
+		t.Fatalf("flushed %q before the window closed", got)
+	}
+
+	clock.advance(time.Minute)
+	timers.fire(0)
+	want := "*Digest:* 3 messages in the last 1m0s\n• ×2 disk full on db-1\n• ×1 cpu high"
+	if got := flushed.of("c1"); len(got) != 1 || got[0] != want {
+		t.Fatalf("flushed %q, want %q", got, want)
+	}
+
+	// the next message opens a new window
+	d.add("c1", time.Minute, "cpu", "cpu high")
+	if len(timers.timers) != 2 {
+		t.Fatalf("a message after the flush opened %d windows in all, want 2", len(timers.timers))
+	}
+	timers.fire(1)
+	if got := flushed.of("c1"); len(got) != 2 || !strings.Contains(got[1], "1 messages") {
+		t.Errorf("flushed %q, want a second digest of one message", got)
+	}
+}
+
+func TestDigesterGroupCap(t *testing.T) {
+	d, timers, _, flushed := newTestDigester()
+
+	for i := range maxDigestGroups + 5 {
+		d.add("c1", time.Minute, fmt.Sprint(i), fmt.Sprintf("message %d", i))
+	}
+	d.add("c1", time.Minute, "0", "message 0")
+	d.add("c1", time.Minute, "overflowing", "message past the cap")
+	timers.fire(0)
+
+	got := flushed.of("c1")
+	if len(got) != 1 {
+		t.Fatalf("flushed %d digests, want 1", len(got))
+	}
+	lines := strings.Split(got[0], "\n")
+	if want := maxDigestGroups + 2; len(lines) != want {
+		t.Fatalf("the digest has %d lines, want %d", len(lines), want)
+	}
+	if !strings.HasPrefix(lines[0], "*Digest:* 107 messages") {
+		t.Errorf("header = %q, want every message counted", lines[0])
+	}
+	if lines[1] != "• ×2 message 0" {
+		t.Errorf("first group = %q, want the repeated message counted twice", lines[1])
+	}
+	if last := lines[len(lines)-1]; last != "• …and 6 more messages" {
+		t.Errorf("last line = %q, want the overflow", last)
+	}
+}
+
+func TestDigesterClose(t *testing.T) {
+	d, timers, _, flushed := newTestDigester()
+
+	d.add("c1", time.Minute, "a", "for c1")
+	d.add("c2", time.Hour, "b", "for c2")
+	d.close()
+
+	for _, id := range []string{"c1", "c2"} {
+		if got := flushed.of(id); len(got) != 1 {
+			t.Errorf("close flushed %d digests of %s, want 1", len(got), id)
+		}
+	}
+	for i, timer := range timers.timers {
+		if !timer.stopped {
+			t.Errorf("close left timer %d running", i)
+		}
+	}
+
+	// a timer firing after close finds nothing left to flush
+	timers.fire(0)
+	if got := flushed.of("c1"); len(got) != 1 {
+		t.Errorf("a late timer flushed %d digests of c1, want 1", len(got))
+	}
+}
+
+func TestDigesterTimer(t *testing.T) {
+	done := make(chan string, 1)
+	d := newDigester(func(connectorID, summary string) { done <- summary }, discard)
+
+	d.add("c1", 10*time.Millisecond, "a", "hello")
+	select {
+	case summary := <-done:
+		if !strings.HasSuffix(summary, "• ×1 hello") {
+			t.Errorf("flushed %q, want the message", summary)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the window was never flushed")
+	}
+}
+
+func TestDigestEscapesMessages(t *testing.T) {
+	d, timers, _, flushed := newTestDigester()
+
+	d.add("c1", time.Minute, "a", "<!channel> deploy by <@U123> & <https://evil.example|click>")
+	timers.fire(0)
+
+	summary := flushed.of("c1")[0]
+	want := "• ×1 &lt;!channel&gt; deploy by &lt;@U123&gt; &amp; &lt;https://evil.example|click&gt;"
+	if !strings.HasSuffix(summary, want) {
+		t.Errorf("summary = %q, want the message escaped as %q", summary, want)
+	}
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+
+	"connector-recruitment/go-server/connectors/errs"
+)
+
+// formatEtag returns the etag of a connector version.
+func formatEtag(version int64) string {
+	return strconv.FormatInt(version, 10)
+}
+
+// parseEtag returns the connector version of an etag precondition, or 0 when there is none.
+func parseEtag(etag string) (int64, error) {
+	if etag == "" {
+		return 0, nil
+	}
+	version, err := strconv.ParseInt(etag, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("%w: %q", errs.ErrInvalidEtag, etag)
+	}
+	return version, nil
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"connector-recruitment/go-server/connectors/errs"
+	pb "connector-recruitment/go-server/connectors/genproto"
+	"connector-recruitment/go-server/connectors/integrations/slack"
+	"connector-recruitment/go-server/connectors/logger"
+	"connector-recruitment/go-server/connectors/storage"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// healthProbeTimeout bounds the probe of a single connector.
+	healthProbeTimeout = 10 * time.Second
+	// healthProbeJitter is the fraction of the interval by which probe rounds are moved
+	// earlier or later, so that replicas started together do not probe together.
+	healthProbeJitter = 0.1
+)
+
+// GetConnectorHealth returns the outcome of the last health probe of the connector, or
+// probes it now when refresh is set.
+func (s *ConnectorService) GetConnectorHealth(ctx context.Context, ID string, refresh bool) (*pb.ConnectorHealth, error) {
+	connector, err := s.storage.GetConnectorByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if refresh {
+		if connector.Health, err = s.probeConnector(ctx, connector); err != nil {
+			return nil, err
+		}
+	}
+	return toProtoConnectorHealth(connector.Health), nil
+}
+
+// probeConnector checks the connector and records the outcome. Only slack connectors can
+// be probed.
+func (s *ConnectorService) probeConnector(ctx context.Context, connector *storage.Connector) (storage.ConnectorHealth, error) {
+	if connector.Type != storage.ConnectorTypeSlack && connector.Type != "" {
+		return connector.Health, fmt.Errorf("%w: %s connectors", errs.ErrHealthProbeUnsupported, connector.Type)
+	}
+
+	health := connector.Health
+	now := time.Now()
+	health.LastCheckedAt = &now
+	health.LastError = ""
+	health.Degraded = false
+	if err := s.checkSlackHealth(ctx, connector); err != nil {
+		health.LastError = err.Error()
+		health.Degraded = errors.Is(err, errChannelUnchecked)
+	}
+	if health.LastError == "" || health.Degraded {
+		health.LastHealthyAt = &now
+	}
+
+	if err := s.storage.SaveConnectorHealth(ctx, connector.ID, health); err != nil {
+		return health, err
+	}
+	return health, nil
+}
+
+// errChannelUnchecked wraps the errors of a probe that found the token valid but could not
+// check the default channel: the connector is degraded rather than down.
+var errChannelUnchecked = errors.New("default channel not checked")
+
+// checkSlackHealth checks that the token is valid with auth.test, which needs no scope, and
+// that the bot is a member of the default channel. The channel check needs the
+// channels:read scope, which a bot posting with chat:write alone lacks.
+func (s *ConnectorService) checkSlackHealth(ctx context.Context, connector *storage.Connector) error {
+	token, err := s.storage.GetConnectorToken(ctx, connector)
+	if err != nil {
+		return err
+	}
+
+	slackClient := s.newSlackClient()
+	if _, err := slackClient.AuthTest(ctx, token); err != nil {
+		return err
+	}
+
+	channel, err := slackClient.ConversationInfo(ctx, token, connector.DefaultChannelID)
+	var slackErr *slack.Error
+	if errors.As(err, &slackErr) && slackErr.Code == slack.ErrCodeMissingScope {
+		return fmt.Errorf("%w: %w", errChannelUnchecked, err)
+	}
+	if err != nil {
+		return err
+	}
+	if channel.IsArchived {
+		return fmt.Errorf("channel %s is archived", connector.DefaultChannelID)
+	}
+	if !channel.IsMember {
+		return fmt.Errorf("not a member of channel %s", connector.DefaultChannelID)
+	}
+	return nil
+}
+
+func toProtoConnectorHealth(h storage.ConnectorHealth) *pb.ConnectorHealth {
+	if h.LastCheckedAt == nil {
+		return nil
+	}
+	health := &pb.ConnectorHealth{
+		Healthy:       h.LastError == "" || h.Degraded,
+		LastCheckedAt: timestamppb.New(*h.LastCheckedAt),
+		LastError:     h.LastError,
+		Degraded:      h.Degraded,
+	}
+	if h.LastHealthyAt != nil {
+		health.LastHealthyAt = timestamppb.New(*h.LastHealthyAt)
+	}
+	return health
+}
+
+// HealthProber periodically probes every active slack connector.
+type HealthProber struct {
+	service     *ConnectorService
+	interval    time.Duration
+	concurrency int
+	logger      logger.Logger
+}
+
+// NewHealthProber creates a prober running a round every interval, give or take the
+// jitter, with at most concurrency probes in flight.
+func NewHealthProber(service *ConnectorService, interval time.Duration, concurrency int, logger logger.Logger) *HealthProber {
+	return &HealthProber{
+		service:     service,
+		interval:    interval,
+		concurrency: max(concurrency, 1),
+		logger:      logger,
+	}
+}
+
+// Run probes the connectors until ctx is done. The first round starts after a random
+// delay of up to one interval.
+func (p *HealthProber) Run(ctx context.Context) {
+	delay := rand.N(p.interval)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		p.probeAll(ctx)
+
+		spread := time.Duration(float64(p.interval) * healthProbeJitter)
+		delay = p.interval - spread + rand.N(2*spread+1)
+	}
+}
+
+func (p *HealthProber) probeAll(ctx context.Context) {
+	connectors, err := p.service.storage.GetAllConnectors(ctx)
+	if err != nil {
+		p.logger.Error("health probe failed to list connectors", "err", err)
+		return
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		probed    int
+		unhealthy int
+		degraded  int
+	)
+	sem := make(chan struct{}, p.concurrency)
+	for _, connector := range connectors {
+		if connector.Status == storage.ConnectorStatusSuspended ||
+			(connector.Type != storage.ConnectorTypeSlack && connector.Type != "") {
+			continue
+		}
+
+		wg.Add(1)
+		go func(connector *storage.Connector) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			probeCtx, cancel := context.WithTimeout(ctx, healthProbeTimeout)
+			defer cancel()
+
+			health, err := p.service.probeConnector(probeCtx, connector)
+			if err != nil {
+				p.logger.Error("health probe failed", "connector-id", connector.ID, "err", err)
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			probed++
+			switch {
+			case health.Degraded:
+				degraded++
+				p.logger.Warn("connector degraded", "connector-id", connector.ID, "err", health.LastError)
+			case health.LastError != "":
+				unhealthy++
+				p.logger.Warn("connector unhealthy", "connector-id", connector.ID, "err", health.LastError)
+			}
+		}(connector)
+	}
+	wg.Wait()
+
+	p.logger.Info("health probe round done", "probed", probed, "unhealthy", unhealthy, "degraded", degraded)
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"connector-recruitment/go-server/connectors/errs"
+	pb "connector-recruitment/go-server/connectors/genproto"
+	"connector-recruitment/go-server/connectors/storage"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultMessagesPageSize = 50
+	maxMessagesPageSize     = 500
+)
+
+// recordDelivery persists a send attempt. Failing to record never fails the send itself,
+// and the record is written even when the caller's context has been cancelled.
+func (s *ConnectorService) recordDelivery(ctx context.Context, connector *storage.Connector, delivery *pb.Delivery, message string, latency time.Duration, sendErr error) {
+	record := &storage.MessageRecord{
+		ConnectorID:   connector.ID,
+		WorkspaceID:   connector.WorkspaceID,
+		ConnectorType: connector.Type,
+		Channel:       delivery.GetChannel(),
+		TS:            delivery.GetTs(),
+		Status:        storage.DeliveryStatusDelivered,
+		StatusCode:    int(delivery.GetStatusCode()),
+		Latency:       latency,
+		ContentHash:   contentHash(message),
+	}
+	if sendErr != nil {
+		record.Status = storage.DeliveryStatusFailed
+		record.Error = sendErr.Error()
+	}
+
+	messageID, err := s.storage.SaveMessage(context.WithoutCancel(ctx), record)
+	if err != nil {
+		s.logger.Error("failed to record message delivery", "connector-id", connector.ID, "err", err)
+		return
+	}
+	if delivery != nil {
+		delivery.MessageId = messageID
+	}
+}
+
+// ListMessages returns a page of the message history, newest first, and the token of the
+// next page, which is empty on the last page.
+func (s *ConnectorService) ListMessages(ctx context.Context, req *pb.ListMessagesRequest) ([]*pb.MessageRecord, string, error) {
+	filter := storage.MessageFilter{
+		ConnectorID: req.ConnectorId,
+		WorkspaceID: req.TenantId,
+		Status:      fromProtoDeliveryStatus(req.Status),
+		Limit:       int(req.PageSize),
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultMessagesPageSize
+	}
+	if filter.Limit > maxMessagesPageSize {
+		filter.Limit = maxMessagesPageSize
+	}
+	if req.StartTime != nil {
+		filter.From = req.StartTime.AsTime()
+	}
+	if req.EndTime != nil {
+		filter.To = req.EndTime.AsTime()
+	}
+	if req.PageToken != "" {
+		cursor, err := decodeMessageCursor(req.PageToken)
+		if err != nil {
+			return nil, "", err
+		}
+		filter.After = cursor
+	}
+
+	// fetch one extra row to know whether there is a next page
+	limit := filter.Limit
+	filter.Limit++
+	records, err := s.storage.ListMessages(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var nextPageToken string
+	if len(records) > limit {
+		records = records[:limit]
+		last := records[limit-1]
+		nextPageToken = encodeMessageCursor(&storage.MessageCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	result := make([]*pb.MessageRecord, 0, len(records))
+	for _, m := range records {
+		result = append(result, toProtoMessageRecord(m))
+	}
+	return result, nextPageToken, nil
+}
+
+func contentHash(message string) string {
+	sum := sha256.Sum256([]byte(message))
+	return hex.EncodeToString(sum[:])
+}
+
+type messageCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+}
+
+func encodeMessageCursor(c *storage.MessageCursor) string {
+	b, _ := json.Marshal(messageCursor{CreatedAt: c.CreatedAt, ID: c.ID})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeMessageCursor(token string) (*storage.MessageCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errs.ErrInvalidPageToken, err)
+	}
+	var c messageCursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("%w: %v", errs.ErrInvalidPageToken, err)
+	}
+	if c.ID == "" || c.CreatedAt.IsZero() {
+		return nil, fmt.Errorf("%w: incomplete cursor", errs.ErrInvalidPageToken)
+	}
+	return &storage.MessageCursor{CreatedAt: c.CreatedAt, ID: c.ID}, nil
+}
+
+func toProtoMessageRecord(m *storage.MessageRecord) *pb.MessageRecord {
+	return &pb.MessageRecord{
+		Id:          m.ID,
+		ConnectorId: m.ConnectorID,
+		TenantId:    m.WorkspaceID,
+		Type:        toProtoConnectorType(m.ConnectorType),
+		Channel:     m.Channel,
+		Ts:          m.TS,
+		Status:      toProtoDeliveryStatus(m.Status),
+		StatusCode:  int32(m.StatusCode),
+		Error:       m.Error,
+		LatencyMs:   m.Latency.Milliseconds(),
+		ContentHash: m.ContentHash,
+		CreatedAt:   timestamppb.New(m.CreatedAt),
+	}
+}
+
+func toProtoDeliveryStatus(st storage.DeliveryStatus) pb.DeliveryStatus {
+	switch st {
+	case storage.DeliveryStatusDelivered:
+		return pb.DeliveryStatus_DELIVERY_STATUS_DELIVERED
+	case storage.DeliveryStatusFailed:
+		return pb.DeliveryStatus_DELIVERY_STATUS_FAILED
+	default:
+		return pb.DeliveryStatus_DELIVERY_STATUS_UNSPECIFIED
+	}
+}
+
+func fromProtoDeliveryStatus(st pb.DeliveryStatus) storage.DeliveryStatus {
+	switch st {
+	case pb.DeliveryStatus_DELIVERY_STATUS_DELIVERED:
+		return storage.DeliveryStatusDelivered
+	case pb.DeliveryStatus_DELIVERY_STATUS_FAILED:
+		return storage.DeliveryStatusFailed
+	default:
+		return ""
+	}
+}
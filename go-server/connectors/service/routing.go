@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"connector-recruitment/go-server/connectors/errs"
+	pb "connector-recruitment/go-server/connectors/genproto"
+	"connector-recruitment/go-server/connectors/storage"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *ConnectorService) CreateRoutingRule(ctx context.Context, rule *pb.RoutingRule) (*pb.RoutingRule, error) {
+	row := fromProtoRoutingRule(rule)
+	if err := s.validateRouteTargets(ctx, row.WorkspaceID, row.Targets); err != nil {
+		return nil, err
+	}
+
+	saved, err := s.storage.SaveRoutingRule(ctx, row)
+	if err != nil {
+		return nil, err
+	}
+	return toProtoRoutingRule(saved), nil
+}
+
+func (s *ConnectorService) GetRoutingRule(ctx context.Context, ID string) (*pb.RoutingRule, error) {
+	rule, err := s.storage.GetRoutingRuleByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+	return toProtoRoutingRule(rule), nil
+}
+
+func (s *ConnectorService) ListRoutingRules(ctx context.Context, tenantID string) ([]*pb.RoutingRule, error) {
+	rules, err := s.storage.GetRoutingRulesByWorkspaceID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*pb.RoutingRule, 0, len(rules))
+	for _, r := range rules {
+		result = append(result, toProtoRoutingRule(r))
+	}
+	return result, nil
+}
+
+// UpdateRoutingRule replaces a rule's name, priority, selector and targets. The tenant
+// of a rule never changes.
+func (s *ConnectorService) UpdateRoutingRule(ctx context.Context, rule *pb.RoutingRule) (*pb.RoutingRule, error) {
+	existing, err := s.storage.GetRoutingRuleByID(ctx, rule.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	row := fromProtoRoutingRule(rule)
+	row.WorkspaceID = existing.WorkspaceID
+	if err := s.validateRouteTargets(ctx, row.WorkspaceID, row.Targets); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.storage.UpdateRoutingRule(ctx, row)
+	if err != nil {
+		return nil, err
+	}
+	return toProtoRoutingRule(updated), nil
+}
+
+func (s *ConnectorService) DeleteRoutingRule(ctx context.Context, ID string) error {
+	return s.storage.DeleteRoutingRule(ctx, ID)
+}
+
+// RouteMessage sends a labelled message to the targets of every tenant rule whose selector
+// matches the labels, highest priority first. Targets reached through several rules
+// receive the message once, see routeTarget. When no rule matches, the message falls back
+// to the default channel of every slack connector of the tenant; webhook and email
+// connectors reach external endpoints, and only get the messages routed to them.
+func (s *ConnectorService) RouteMessage(ctx context.Context, tenantID string, labels map[string]string, message string) ([]*pb.BroadcastResult, error) {
+	rules, err := s.storage.GetRoutingRulesByWorkspaceID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	connectors, err := s.storage.GetConnectorsByWorkspaceID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*storage.Connector, len(connectors))
+	for _, c := range connectors {
+		byID[c.ID] = c
+	}
+
+	var jobs []deliveryJob
+	seen := make(map[storage.RouteTarget]bool)
+	for _, rule := range rules {
+		if !rule.Matches(labels) {
+			continue
+		}
+		s.logger.Debug("routing rule matched", "rule-id", rule.ID, "tenant", tenantID)
+		for _, target := range rule.Targets {
+			connector := byID[target.ConnectorID]
+			target = routeTarget(target, connector)
+			if seen[target] {
+				continue
+			}
+			seen[target] = true
+			jobs = append(jobs, deliveryJob{connectorID: target.ConnectorID, connector: connector, channelID: target.ChannelID})
+		}
+	}
+
+	if len(jobs) == 0 {
+		s.logger.Debug("no routing rule matched, falling back to default channels", "tenant", tenantID)
+		for _, c := range connectors {
+			if c.Type == storage.ConnectorTypeSlack || c.Type == "" {
+				jobs = append(jobs, deliveryJob{connectorID: c.ID, connector: c})
+			}
+		}
+	}
+
+	return s.fanOut(ctx, jobs, outgoing{text: message}), nil
+}
+
+// routeTarget resolves the channel a target delivers to, so that targets sending to the same
+// place compare equal: an empty channel is the default channel of the connector, and only
+// slack connectors have channels. A target whose connector is gone is kept as is; its
+// delivery fails.
+func routeTarget(target storage.RouteTarget, connector *storage.Connector) storage.RouteTarget {
+	switch {
+	case connector == nil:
+		return target
+	case connector.Type != storage.ConnectorTypeSlack && connector.Type != "":
+		target.ChannelID = ""
+	case target.ChannelID == "":
+		target.ChannelID = connector.DefaultChannelID
+	}
+	return target
+}
+
+// validateRouteTargets ensures every target is an existing connector of the tenant.
+func (s *ConnectorService) validateRouteTargets(ctx context.Context, tenantID string, targets []storage.RouteTarget) error {
+	for _, target := range targets {
+		conn, err := s.storage.GetConnectorByID(ctx, target.ConnectorID)
+		if err != nil {
+			if errors.Is(err, errs.ErrConnectorNotFound) {
+				return errs.NewInvalidRouteTargetError(target.ConnectorID, "does not exist")
+			}
+			return err
+		}
+		if conn.WorkspaceID != tenantID {
+			return errs.NewInvalidRouteTargetError(target.ConnectorID, "belongs to another tenant")
+		}
+	}
+	return nil
+}
+
+func toProtoRoutingRule(r *storage.RoutingRule) *pb.RoutingRule {
+	targets := make([]*pb.RouteTarget, 0, len(r.Targets))
+	for _, t := range r.Targets {
+		targets = append(targets, &pb.RouteTarget{ConnectorId: t.ConnectorID, ChannelId: t.ChannelID})
+	}
+	return &pb.RoutingRule{
+		Id:          r.ID,
+		TenantId:    r.WorkspaceID,
+		Name:        r.Name,
+		Priority:    int32(r.Priority),
+		MatchLabels: r.MatchLabels,
+		Targets:     targets,
+		CreatedAt:   timestamppb.New(r.CreatedAt),
+		UpdatedAt:   timestamppb.New(r.UpdatedAt),
+	}
+}
+
+func fromProtoRoutingRule(r *pb.RoutingRule) *storage.RoutingRule {
+	targets := make([]storage.RouteTarget, 0, len(r.Targets))
+	for _, t := range r.Targets {
+		targets = append(targets, storage.RouteTarget{ConnectorID: t.ConnectorId, ChannelID: t.ChannelId})
+	}
+	return &storage.RoutingRule{
+		ID:          r.Id,
+		WorkspaceID: r.TenantId,
+		Name:        r.Name,
+		Priority:    int(r.Priority),
+		MatchLabels: r.MatchLabels,
+		Targets:     targets,
+	}
+}
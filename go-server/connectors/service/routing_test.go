@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"connector-recruitment/go-server/connectors/storage"
+)
+
+func TestRouteMessageFallback(t *testing.T) {
+	s, fake, tenant := newTestService(t)
+	ctx := context.Background()
+
+	var webhookCalls atomic.Int32
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		webhookCalls.Add(1)
+	}))
+	defer hook.Close()
+
+	var smtpConnections atomic.Int32
+	smtp, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer smtp.Close()
+	go func() {
+		for {
+			conn, err := smtp.Accept()
+			if err != nil {
+				return
+			}
+			smtpConnections.Add(1)
+			conn.Close()
+		}
+	}()
+
+	slackConnector := saveConnector(t, s, &storage.Connector{
+		WorkspaceID:      tenant.ID,
+		DefaultChannelID: "C-default",
+		Type:             storage.ConnectorTypeSlack,
+	})
+	webhookConnector := saveConnector(t, s, &storage.Connector{
+		WorkspaceID: tenant.ID,
+		Type:        storage.ConnectorTypeWebhook,
+		Settings:    storage.Settings{Webhook: &storage.WebhookSettings{URL: hook.URL}},
+	})
+	saveConnector(t, s, &storage.Connector{
+		WorkspaceID: tenant.ID,
+		Type:        storage.ConnectorTypeEmail,
+		Settings: storage.Settings{Email: &storage.EmailSettings{
+			SMTPHost: "127.0.0.1",
+			SMTPPort: smtp.Addr().(*net.TCPAddr).Port,
+			From:     "alerts@example.com",
+			To:       []string{"ops@example.com"},
+		}},
+	})
+	if _, err := s.storage.SaveRoutingRule(ctx, &storage.RoutingRule{
+		WorkspaceID: tenant.ID,
+		Name:        "deploys",
+		MatchLabels: map[string]string{"kind": "deploy"},
+		Targets:     []storage.RouteTarget{{ConnectorID: webhookConnector.ID}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := s.RouteMessage(ctx, tenant.ID, map[string]string{"kind": "unknown"}, "hello")
+	if err != nil {
+		t.Fatalf("RouteMessage: %v", err)
+	}
+	if len(results) != 1 || results[0].ConnectorId != slackConnector.ID || results[0].Error != "" {
+		t.Fatalf("RouteMessage = %v, want a single delivery through the slack connector", results)
+	}
+	if posts := fake.messages(); len(posts) != 1 || posts[0].Channel != "C-default" {
+		t.Errorf("slack received %+v, want one message to the default channel", posts)
+	}
+	if n := webhookCalls.Load(); n != 0 {
+		t.Errorf("the webhook was called %d times by the fallback", n)
+	}
+	if n := smtpConnections.Load(); n != 0 {
+		t.Errorf("the SMTP server was connected to %d times by the fallback", n)
+	}
+
+	// a matching rule still reaches its webhook
+	results, err = s.RouteMessage(ctx, tenant.ID, map[string]string{"kind": "deploy"}, "hello")
+	if err != nil {
+		t.Fatalf("RouteMessage: %v", err)
+	}
+	if len(results) != 1 || results[0].ConnectorId != webhookConnector.ID || webhookCalls.Load() != 1 {
+		t.Errorf("RouteMessage = %v, want a single delivery through the webhook", results)
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"connector-recruitment/go-server/connectors/integrations/slack"
+	"connector-recruitment/go-server/connectors/storage"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// newTestService returns a service over a memory storage holding a tenant, posting to a
+// fake Slack.
+func newTestService(t *testing.T) (*ConnectorService, *fakeSlack, *storage.Tenant) {
+	t.Helper()
+	namer, err := storage.NewSecretNamer("", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := storage.NewMemoryStorage(storage.NewMemorySecretStore(), namer, discard)
+	tenant, err := store.SaveTenant(context.Background(), &storage.Tenant{ID: "tenant", Name: "tenant", Status: storage.TenantStatusActive})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := newFakeSlack(t)
+	s := NewConnectorService(store, discard)
+	s.slackURL = fake.URL
+	t.Cleanup(s.Close)
+	return s, fake, tenant
+}
+
+// saveConnector stores c for the tenant and returns it as stored.
+func saveConnector(t *testing.T, s *ConnectorService, c *storage.Connector) *storage.Connector {
+	t.Helper()
+	ctx := context.Background()
+	if c.Token == "" {
+		c.Token = "token"
+	}
+	id, err := s.storage.SaveConnector(ctx, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := s.storage.GetConnectorByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return saved
+}
+
+// fakeSlack answers chat.postMessage, failing the posts to the channels in errors with
+// their error code.
+type fakeSlack struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	posts  []slack.Message
+	errors map[string]string
+}
+
+func newFakeSlack(t *testing.T) *fakeSlack {
+	f := &fakeSlack{errors: make(map[string]string)}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg slack.Message
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.mu.Lock()
+		f.posts = append(f.posts, msg)
+		code := f.errors[msg.Channel]
+		f.mu.Unlock()
+
+		resp := slack.SlackResponse{Ok: code == "", Error: code, Channel: msg.Channel, TS: "1700000000.000100"}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// fail makes the posts to channel fail with code, or succeed again when code is empty.
+func (f *fakeSlack) fail(channel, code string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.errors[channel] = code
+}
+
+// messages returns the messages posted so far.
+func (f *fakeSlack) messages() []slack.Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]slack.Message(nil), f.posts...)
+}
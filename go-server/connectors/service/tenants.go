@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"connector-recruitment/go-server/connectors/errs"
+	pb "connector-recruitment/go-server/connectors/genproto"
+	"connector-recruitment/go-server/connectors/storage"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *ConnectorService) CreateTenant(ctx context.Context, tenant *pb.Tenant) (*pb.Tenant, error) {
+	saved, err := s.storage.SaveTenant(ctx, fromProtoTenant(tenant))
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("tenant created", "tenant", saved.ID)
+	return toProtoTenant(saved), nil
+}
+
+func (s *ConnectorService) GetTenant(ctx context.Context, ID string) (*pb.Tenant, error) {
+	tenant, err := s.storage.GetTenantByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+	return toProtoTenant(tenant), nil
+}
+
+func (s *ConnectorService) ListTenants(ctx context.Context) ([]*pb.Tenant, error) {
+	tenants, err := s.storage.GetAllTenants(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*pb.Tenant, 0, len(tenants))
+	for _, t := range tenants {
+		result = append(result, toProtoTenant(t))
+	}
+	return result, nil
+}
+
+// UpdateTenant changes the fields of a tenant listed in mask, or its name, status and
+// settings when mask is empty. Suspending a tenant keeps its connectors working, but no
+// connector can be added until it is active again.
+func (s *ConnectorService) UpdateTenant(ctx context.Context, tenant *pb.Tenant, mask *fieldmaskpb.FieldMask) (*pb.Tenant, error) {
+	fields := []storage.TenantField{storage.TenantFieldName, storage.TenantFieldStatus, storage.TenantFieldSettings}
+	if len(mask.GetPaths()) > 0 {
+		fields = fields[:0]
+		for _, path := range mask.GetPaths() {
+			if field := storage.TenantField(path); !slices.Contains(fields, field) {
+				fields = append(fields, field)
+			}
+		}
+	}
+	updated, err := s.storage.UpdateTenant(ctx, fromProtoTenant(tenant), fields)
+	if err != nil {
+		return nil, err
+	}
+	return toProtoTenant(updated), nil
+}
+
+// checkTenant ensures connectors can be created for the tenant.
+func (s *ConnectorService) checkTenant(ctx context.Context, tenantID string) error {
+	tenant, err := s.storage.GetTenantByID(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if tenant.Status == storage.TenantStatusSuspended {
+		return errs.NewTenantSuspendedError(tenantID)
+	}
+	return nil
+}
+
+func toProtoTenant(t *storage.Tenant) *pb.Tenant {
+	return &pb.Tenant{
+		Id:        t.ID,
+		Name:      t.Name,
+		Status:    toProtoTenantStatus(t.Status),
+		Settings:  t.Settings,
+		CreatedAt: timestamppb.New(t.CreatedAt),
+		UpdatedAt: timestamppb.New(t.UpdatedAt),
+	}
+}
+
+func fromProtoTenant(t *pb.Tenant) *storage.Tenant {
+	return &storage.Tenant{
+		ID:       t.Id,
+		Name:     t.Name,
+		Status:   fromProtoTenantStatus(t.Status),
+		Settings: t.Settings,
+	}
+}
+
+func toProtoTenantStatus(status storage.TenantStatus) pb.TenantStatus {
+	if status == storage.TenantStatusSuspended {
+		return pb.TenantStatus_TENANT_STATUS_SUSPENDED
+	}
+	return pb.TenantStatus_TENANT_STATUS_ACTIVE
+}
+
+func fromProtoTenantStatus(status pb.TenantStatus) storage.TenantStatus {
+	if status == pb.TenantStatus_TENANT_STATUS_SUSPENDED {
+		return storage.TenantStatusSuspended
+	}
+	return storage.TenantStatusActive
+}
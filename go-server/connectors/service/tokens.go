@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"connector-recruitment/go-server/connectors/errs"
+	pb "connector-recruitment/go-server/connectors/genproto"
+	"connector-recruitment/go-server/connectors/integrations/slack"
+	"connector-recruitment/go-server/connectors/storage"
+)
+
+// RotateConnectorToken replaces the connector's secret, keeping the replaced one for
+// RollbackConnectorToken. With validate, a slack token is checked with auth.test first and
+// nothing is stored when it is rejected. A connector suspended because of its token is
+// reactivated. A non-empty etag must be the connector's current etag.
+func (s *ConnectorService) RotateConnectorToken(ctx context.Context, ID, token, etag string, validate bool) (*pb.Connector, error) {
+	version, err := parseEtag(etag)
+	if err != nil {
+		return nil, err
+	}
+	connector, err := s.storage.GetConnectorByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+	// Fail fast on a stale etag, before validating the token with slack. The storage
+	// checks it again when it stores the token.
+	if version != 0 && version != connector.Version {
+		return nil, errs.NewVersionMismatchError(ID, connector.Version)
+	}
+
+	if validate {
+		if connector.Type != storage.ConnectorTypeSlack && connector.Type != "" {
+			return nil, fmt.Errorf("%w: only slack tokens can be validated", errs.ErrTokenValidationFailed)
+		}
+		if _, err := s.newSlackClient().AuthTest(ctx, token); err != nil {
+			return nil, fmt.Errorf("%w: %w", errs.ErrTokenValidationFailed, err)
+		}
+	}
+
+	if err := s.storage.RotateConnectorToken(ctx, ID, token, version); err != nil {
+		return nil, err
+	}
+	s.logger.Info("connector token rotated", "connector-id", ID, "validated", validate)
+	return s.afterTokenChange(ctx, connector)
+}
+
+// RollbackConnectorToken restores the secret replaced by the last rotation. Rolling back
+// twice restores the rotated secret. A non-empty etag must be the connector's current etag.
+func (s *ConnectorService) RollbackConnectorToken(ctx context.Context, ID, etag string) (*pb.Connector, error) {
+	version, err := parseEtag(etag)
+	if err != nil {
+		return nil, err
+	}
+	connector, err := s.storage.GetConnectorByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.RollbackConnectorToken(ctx, ID, version); err != nil {
+		return nil, err
+	}
+	s.logger.Info("connector token rolled back", "connector-id", ID)
+	return s.afterTokenChange(ctx, connector)
+}
+
+// afterTokenChange gives the connector a fresh start with its new token: the circuit
+// breaker is reset, and a suspension caused by the token is lifted. A suspension for a
+// missing channel stays, since a token does not bring the channel back.
+func (s *ConnectorService) afterTokenChange(ctx context.Context, connector *storage.Connector) (*pb.Connector, error) {
+	s.breaker.success(connector.ID)
+
+	if connector.Status == storage.ConnectorStatusSuspended && connector.StatusReason != slack.ErrCodeChannelNotFound {
+		if err := s.storage.SetConnectorStatus(ctx, connector.ID, storage.ConnectorStatusActive, ""); err != nil {
+			return nil, err
+		}
+		s.logger.Info("connector reactivated", "connector-id", connector.ID)
+	}
+
+	updated, err := s.storage.GetConnectorByID(ctx, connector.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toProtoConnector(updated), nil
+}
@@ -0,0 +1,241 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"connector-recruitment/go-server/connectors/audit"
+	"connector-recruitment/go-server/connectors/errs"
+	"connector-recruitment/go-server/connectors/logger"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const auditEventColumns = `id, actor, peer_address, method, action, connector_id, workspace_id, diff, created_at`
+
+// lockConnector reads a connector and locks its row until the end of tx. A non-zero
+// expectedVersion must be the connector's version.
+func lockConnector(ctx context.Context, tx pgx.Tx, connectorID string, expectedVersion int64) (*Connector, error) {
+	query := `SELECT ` + connectorColumns + ` FROM connectors WHERE id = $1 FOR UPDATE`
+	c, err := scanConnector(tx.QueryRow(ctx, query, connectorID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NewConnectorNotFoundError(connectorID)
+		}
+		return nil, fmt.Errorf("failed to lock connector %s: %w", connectorID, err)
+	}
+	if expectedVersion != 0 && c.Version != expectedVersion {
+		return nil, errs.NewVersionMismatchError(connectorID, c.Version)
+	}
+	return c, nil
+}
+
+// updateConnector applies set, an assignment list whose parameters start at $2, to a
+// connector and records the change in the audit log, in one transaction. The version is
+// bumped, and checked against a non-zero expectedVersion.
+func (s *SqlStorage) updateConnector(ctx context.Context, action AuditAction, connectorID string, expectedVersion int64, set string, args ...any) (*Connector, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := lockConnector(ctx, tx, connectorID, expectedVersion)
+	if err != nil {
+		return nil, err
+	}
+	after, err := updateLockedConnector(ctx, tx, action, before, set, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return after, nil
+}
+
+// updateLockedConnector is updateConnector for a connector locked within tx by
+// lockConnector, before being its state then.
+func updateLockedConnector(ctx context.Context, tx pgx.Tx, action AuditAction, before *Connector, set string, args ...any) (*Connector, error) {
+	query := `
+		UPDATE connectors
+		SET ` + set + `, version = version + 1
+		WHERE id = $1
+		RETURNING ` + connectorColumns
+	after, err := scanConnector(tx.QueryRow(ctx, query, append([]any{before.ID}, args...)...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update connector %s: %w", before.ID, err)
+	}
+	if err := recordAuditEvent(ctx, tx, action, before, after); err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+// recordAuditEvent writes the change of a connector, made by the actor of ctx, within tx.
+// before is nil for a creation, after for a deletion.
+func recordAuditEvent(ctx context.Context, tx pgx.Tx, action AuditAction, before, after *Connector) error {
+	subject := after
+	if subject == nil {
+		subject = before
+	}
+	diff, err := connectorDiff(before, after)
+	if err != nil {
+		return err
+	}
+
+	actor := audit.FromContext(ctx)
+	query := `
+		INSERT INTO audit_events (actor, peer_address, method, action, connector_id, workspace_id, diff)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err = tx.Exec(ctx, query,
+		actor.ID,
+		actor.PeerAddress,
+		actor.Method,
+		action,
+		subject.ID,
+		subject.WorkspaceID,
+		diff,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+	return nil
+}
+
+// auditFields are the fields of a connector compared by the audit log. The token is never
+// part of them, and webhook header values, which may hold credentials, are redacted.
+func auditFields(c *Connector) map[string]any {
+	var webhook *WebhookSettings
+	if c.Settings.Webhook != nil {
+		redacted := *c.Settings.Webhook
+		redacted.Headers = make(map[string]string, len(c.Settings.Webhook.Headers))
+		for k := range c.Settings.Webhook.Headers {
+			redacted.Headers[k] = logger.Redacted
+		}
+		webhook = &redacted
+	}
+	return map[string]any{
+		"tenant_id":          c.WorkspaceID,
+		"default_channel_id": c.DefaultChannelID,
+		"type":               c.Type,
+		"webhook":            webhook,
+		"email":              c.Settings.Email,
+		"digest":             c.Settings.Digest,
+		"status":             c.Status,
+		"status_reason":      c.StatusReason,
+		"secret_name":        c.SecretName,
+		"version":            c.Version,
+	}
+}
+
+// connectorDiff returns the fields that differ between before and after. A nil connector
+// has no fields, so a creation or deletion lists them all on one side.
+func connectorDiff(before, after *Connector) (map[string]AuditChange, error) {
+	encode := func(c *Connector, name string) (json.RawMessage, error) {
+		if c == nil {
+			return nil, nil
+		}
+		value, err := json.Marshal(auditFields(c)[name])
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		return value, nil
+	}
+
+	subject := after
+	if subject == nil {
+		subject = before
+	}
+	diff := make(map[string]AuditChange)
+	for name := range auditFields(subject) {
+		var change AuditChange
+		var err error
+		if change.Before, err = encode(before, name); err != nil {
+			return nil, err
+		}
+		if change.After, err = encode(after, name); err != nil {
+			return nil, err
+		}
+		if before != nil && after != nil && bytes.Equal(change.Before, change.After) {
+			continue
+		}
+		diff[name] = change
+	}
+	return diff, nil
+}
+
+// ListAuditEvents returns the audit events matching the filter, newest first.
+func (s *SqlStorage) ListAuditEvents(ctx context.Context, f AuditFilter) ([]*AuditEvent, error) {
+	var (
+		conds []string
+		args  []any
+	)
+	where := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if f.ConnectorID != "" {
+		where("connector_id = $%d", f.ConnectorID)
+	}
+	if f.WorkspaceID != "" {
+		where("workspace_id = $%d", f.WorkspaceID)
+	}
+	if f.Actor != "" {
+		where("actor = $%d", f.Actor)
+	}
+	if !f.From.IsZero() {
+		where("created_at >= $%d", f.From)
+	}
+	if !f.To.IsZero() {
+		where("created_at < $%d", f.To)
+	}
+	if f.After != nil {
+		args = append(args, f.After.CreatedAt, f.After.ID)
+		conds = append(conds, fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+
+	query := `
+		SELECT ` + auditEventColumns + `
+		FROM audit_events`
+	if len(conds) > 0 {
+		query += "\n\t\tWHERE " + strings.Join(conds, " AND ")
+	}
+	args = append(args, f.Limit)
+	query += fmt.Sprintf("\n\t\tORDER BY created_at DESC, id DESC \n\t\tLIMIT $%d", len(args))
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*AuditEvent
+	for rows.Next() {
+		e := &AuditEvent{}
+		err := rows.Scan(
+			&e.ID,
+			&e.Actor,
+			&e.PeerAddress,
+			&e.Method,
+			&e.Action,
+			&e.ConnectorID,
+			&e.WorkspaceID,
+			&e.Diff,
+			&e.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit event row: %w", err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return events, nil
+}
@@ -4,29 +4,34 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"sync"
 
+	"connector-recruitment/go-server/connectors/errs"
 	"connector-recruitment/go-server/connectors/logger"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/secretsmanager"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+const connectorColumns = `id, workspace_id, default_channel_id, connector_type, settings, status, status_reason,
+	last_checked_at, last_healthy_at, last_health_error, health_degraded, secret_name, version, created_at, updated_at`
+
 // SqlStorage is responsible for database and secrets operations.
 type SqlStorage struct {
-	logger   logger.Logger
-	db       *pgxpool.Pool
-	smClient *secretsmanager.SecretsManager
+	logger      logger.Logger
+	db          *pgxpool.Pool
+	secrets     SecretStore
+	secretNamer SecretNamer
+	replica     Replica
 }
 
-// NewSqlStorage creates a new SqlStorage instance.
-func NewSqlStorage(db *pgxpool.Pool, smClient *secretsmanager.SecretsManager, logger logger.Logger) *SqlStorage {
-	return &SqlStorage{db: db, smClient: smClient, logger: logger}
+// NewSqlStorage creates a new SqlStorage instance. replica may be nil.
+func NewSqlStorage(db *pgxpool.Pool, replica Replica, secrets SecretStore, secretNamer SecretNamer, logger logger.Logger) *SqlStorage {
+	return &SqlStorage{db: db, replica: replica, secrets: secrets, secretNamer: secretNamer, logger: logger}
 }
 
-// SaveConnector inserts a new connector into the database and creates its secret in AWS Secrets Manager atomically.
+// SaveConnector inserts a new connector into the database and creates its secret in the secret store. The
+// connector is only committed once the secret is created; with a TxSecretStore, both are written in one transaction.
 func (s *SqlStorage) SaveConnector(ctx context.Context, connector *Connector) (connectorID string, err error) {
 	// Begin a transaction.
 	tx, err := s.db.Begin(ctx)
@@ -41,27 +46,49 @@ func (s *SqlStorage) SaveConnector(ctx context.Context, connector *Connector) (c
 	}()
 
 	// Insert connector record using the transaction.
+	createdAt, updatedAt := connector.creationTimes()
 	query := `
-		INSERT INTO connectors (workspace_id, default_channel_id, created_at, updated_at) 
-		VALUES ($1, $2, $3, $4) 
+		INSERT INTO connectors (workspace_id, default_channel_id, connector_type, settings, created_at, updated_at) 
+		VALUES ($1, $2, $3, $4, $5, $6) 
 		RETURNING id`
 	err = tx.QueryRow(ctx, query,
 		connector.WorkspaceID,
 		connector.DefaultChannelID,
-		connector.CreatedAt,
-		connector.UpdatedAt,
+		connector.Type,
+		connector.Settings,
+		createdAt,
+		updatedAt,
 	).Scan(&connectorID)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+			return "", errs.NewTenantNotFoundError(connector.WorkspaceID)
+		}
 		return "", fmt.Errorf("failed to save connector: %w", err)
 	}
 
-	// Create secret in AWS Secrets Manager.
-	_, err = s.smClient.CreateSecretWithContext(ctx, &secretsmanager.CreateSecretInput{
-		Name:         aws.String(connectorID),
-		SecretString: aws.String(connector.Token),
-	})
+	// Name the secret after the connector, now that it has an ID.
+	saved := *connector
+	saved.ID = connectorID
+	var secretName string
+	if connector.Token != "" {
+		secretName = s.secretNamer.Name(&saved)
+	}
+	query = `UPDATE connectors SET secret_name = $2 WHERE id = $1 RETURNING ` + connectorColumns
+	created, err := scanConnector(tx.QueryRow(ctx, query, connectorID, secretName))
 	if err != nil {
-		return "", fmt.Errorf("failed to save slack token: %w", err)
+		return "", fmt.Errorf("failed to save connector secret name: %w", err)
+	}
+	if err = recordAuditEvent(ctx, tx, AuditActionCreate, nil, created); err != nil {
+		return "", err
+	}
+
+	// Create the secret in the secret store.
+	if secretName != "" {
+		err = s.secretsIn(tx).PutSecret(ctx, secretName, connector.Token, s.secretNamer.Tags(&saved))
+		if err != nil {
+			return "", fmt.Errorf("failed to save connector secret: %w", err)
+		}
 	}
 
 	// Commit the transaction.
@@ -69,64 +96,88 @@ func (s *SqlStorage) SaveConnector(ctx context.Context, connector *Connector) (c
 		return "", fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	s.logger.Debug("Created secret", "secret-name", connectorID)
+	if secretName != "" {
+		s.logger.Debug("Created secret", "secret-name", secretName)
+	}
 	return connectorID, nil
 }
 
-// GetConnectorByID retrieves a connector by its ID and also fetches its secret token.
+// GetConnectorByID retrieves a connector by its ID. The token is not fetched, see GetConnectorToken.
 func (s *SqlStorage) GetConnectorByID(ctx context.Context, connectorID string) (*Connector, error) {
-	c := &Connector{}
 	query := `
-		SELECT id, workspace_id, default_channel_id, created_at, updated_at 
+		SELECT ` + connectorColumns + ` 
 		FROM connectors 
 		WHERE id = $1`
-	err := s.db.QueryRow(ctx, query, connectorID).Scan(
-		&c.ID,
-		&c.WorkspaceID,
-		&c.DefaultChannelID,
-		&c.CreatedAt,
-		&c.UpdatedAt,
-	)
+	c, err := scanConnector(s.reader(ctx).QueryRow(ctx, query, connectorID))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("connector with ID %s not found: %w", connectorID, err)
+			return nil, errs.NewConnectorNotFoundError(connectorID)
 		}
 		return nil, fmt.Errorf("failed to get connector by ID: %w", err)
 	}
+	return c, nil
+}
 
-	// Fetch secret value from AWS Secrets Manager.
-	result, err := s.smClient.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{
-		SecretId: aws.String(c.ID),
-	})
+// GetConnectorToken fetches the secret token of a connector from the secret store. It is
+// empty when the connector has no secret.
+func (s *SqlStorage) GetConnectorToken(ctx context.Context, connector *Connector) (string, error) {
+	if connector.SecretName == "" {
+		return "", nil
+	}
+	token, err := s.secrets.GetSecret(ctx, connector.SecretName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get secret value for connector %s: %w", connectorID, err)
+		return "", fmt.Errorf("failed to get secret value for connector %s: %w", connector.ID, err)
 	}
-	c.Token = aws.StringValue(result.SecretString)
-	return c, nil
+	return token, nil
 }
 
-// scanRowsIntoConnector scans a pgx.Rows result into a Connector struct.
-func scanRowsIntoConnector(rows pgx.Rows) (*Connector, error) {
+// scanConnector scans a row of connectorColumns into a Connector struct.
+func scanConnector(row pgx.Row) (*Connector, error) {
 	connector := &Connector{}
-	err := rows.Scan(
+	err := row.Scan(
 		&connector.ID,
 		&connector.WorkspaceID,
 		&connector.DefaultChannelID,
+		&connector.Type,
+		&connector.Settings,
+		&connector.Status,
+		&connector.StatusReason,
+		&connector.Health.LastCheckedAt,
+		&connector.Health.LastHealthyAt,
+		&connector.Health.LastError,
+		&connector.Health.Degraded,
+		&connector.SecretName,
+		&connector.Version,
 		&connector.CreatedAt,
 		&connector.UpdatedAt,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to scan connector row: %w", err)
+		return nil, err
 	}
 	return connector, nil
 }
 
-// GetAllConnectors retrieves all connectors and then concurrently fetches their secret tokens.
+// GetAllConnectors retrieves all connectors, without their tokens.
 func (s *SqlStorage) GetAllConnectors(ctx context.Context) ([]*Connector, error) {
 	query := `
-		SELECT id, workspace_id, default_channel_id, created_at, updated_at 
+		SELECT ` + connectorColumns + ` 
 		FROM connectors`
-	rows, err := s.db.Query(ctx, query)
+	return s.queryConnectors(ctx, query)
+}
+
+// GetConnectorsByWorkspaceID retrieves every connector of a workspace, without their tokens.
+func (s *SqlStorage) GetConnectorsByWorkspaceID(ctx context.Context, workspaceID string) ([]*Connector, error) {
+	query := `
+		SELECT ` + connectorColumns + ` 
+		FROM connectors 
+		WHERE workspace_id = $1 
+		ORDER BY created_at, id`
+	return s.queryConnectors(ctx, query, workspaceID)
+}
+
+// queryConnectors runs a connectors query and scans the rows.
+func (s *SqlStorage) queryConnectors(ctx context.Context, query string, args ...any) ([]*Connector, error) {
+	rows, err := s.reader(ctx).Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query connectors: %w", err)
 	}
@@ -134,61 +185,120 @@ func (s *SqlStorage) GetAllConnectors(ctx context.Context) ([]*Connector, error)
 
 	var connectors []*Connector
 	for rows.Next() {
-		c, err := scanRowsIntoConnector(rows)
+		c, err := scanConnector(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan row: %w", err)
+			return nil, fmt.Errorf("failed to scan connector row: %w", err)
 		}
 		connectors = append(connectors, c)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("rows iteration error: %w", err)
 	}
-
-	// Fetch secrets concurrently for each connector.
-	var wg sync.WaitGroup
-	for _, c := range connectors {
-		wg.Add(1)
-		go func(c *Connector) {
-			defer wg.Done()
-			res, err := s.smClient.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{
-				SecretId: aws.String(c.ID),
-			})
-			if err != nil {
-				s.logger.Error("Failed to get secret", "ID", c.ID, "error", err)
-				return
-			}
-			c.Token = aws.StringValue(res.SecretString)
-		}(c)
-	}
-	wg.Wait()
 	return connectors, nil
 }
 
-// DeleteConnector removes a connector by ID from the database and deletes its secret from AWS Secrets Manager atomically.
-func (s *SqlStorage) DeleteConnector(ctx context.Context, ID string) error {
-	// Begin a transaction.
+// RotateConnectorToken makes token the current version of the connector's secret, keeping
+// the replaced one as the previous version. A non-zero expectedVersion must be the
+// connector's version.
+func (s *SqlStorage) RotateConnectorToken(ctx context.Context, connectorID, token string, expectedVersion int64) error {
+	return s.changeSecret(ctx, AuditActionRotateToken, connectorID, expectedVersion, func(secrets SecretStore, name string) error {
+		if err := secrets.RotateSecret(ctx, name, token); err != nil {
+			return fmt.Errorf("failed to rotate secret of connector %s: %w", connectorID, err)
+		}
+		return nil
+	})
+}
+
+// RollbackConnectorToken swaps the current and the previous versions of the connector's
+// secret. A non-zero expectedVersion must be the connector's version.
+func (s *SqlStorage) RollbackConnectorToken(ctx context.Context, connectorID string, expectedVersion int64) error {
+	return s.changeSecret(ctx, AuditActionRollbackToken, connectorID, expectedVersion, func(secrets SecretStore, name string) error {
+		if err := secrets.RollbackSecret(ctx, name); err != nil {
+			return fmt.Errorf("failed to roll back secret of connector %s: %w", connectorID, err)
+		}
+		return nil
+	})
+}
+
+// changeSecret runs change on the secret of a connector while its row is locked, then bumps
+// the version and updated_at of the connector and records the change as action, in the
+// same transaction. Nothing is recorded when change fails.
+func (s *SqlStorage) changeSecret(ctx context.Context, action AuditAction, connectorID string, expectedVersion int64, change func(secrets SecretStore, secretName string) error) error {
 	tx, err := s.db.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to start transaction: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
-	// Delete connector from the database.
-	query := `DELETE FROM connectors WHERE id = $1`
-	result, err := tx.Exec(ctx, query, ID)
+	before, err := lockConnector(ctx, tx, connectorID, expectedVersion)
 	if err != nil {
-		return fmt.Errorf("failed to delete connector with ID %s: %w", ID, err)
+		return err
+	}
+	if before.SecretName == "" {
+		return errs.NewNoSecretError(connectorID)
+	}
+	if err := change(s.secretsIn(tx), before.SecretName); err != nil {
+		return err
+	}
+	if _, err := updateLockedConnector(ctx, tx, action, before, `updated_at = NOW()`); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	s.secretCommitted(before.SecretName)
+	return nil
+}
+
+// SetConnectorStatus changes the status of a connector, along with the reason for it.
+func (s *SqlStorage) SetConnectorStatus(ctx context.Context, connectorID string, status ConnectorStatus, reason string) error {
+	_, err := s.updateConnector(ctx, AuditActionSetStatus, connectorID, 0, `status = $2, status_reason = $3`, status, reason)
+	return err
+}
+
+// SaveConnectorHealth records the outcome of a health probe of a connector. It does not bump
+// the version: probes run every few minutes and would make every etag stale.
+func (s *SqlStorage) SaveConnectorHealth(ctx context.Context, connectorID string, health ConnectorHealth) error {
+	query := `
+		UPDATE connectors 
+		SET last_checked_at = $2, last_healthy_at = $3, last_health_error = $4, health_degraded = $5 
+		WHERE id = $1`
+	result, err := s.db.Exec(ctx, query, connectorID, health.LastCheckedAt, health.LastHealthyAt, health.LastError, health.Degraded)
+	if err != nil {
+		return fmt.Errorf("failed to save health of connector %s: %w", connectorID, err)
 	}
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("no connector found with ID %s", ID)
+		return errs.NewConnectorNotFoundError(connectorID)
+	}
+	return nil
+}
+
+// DeleteConnector removes a connector by ID from the database and deletes its secret from the secret store. The
+// deletion is only committed once the secret is deleted, or found missing already; with a TxSecretStore, both are
+// deleted in one transaction. A non-zero expectedVersion must be the connector's version.
+func (s *SqlStorage) DeleteConnector(ctx context.Context, ID string, expectedVersion int64) error {
+	// Begin a transaction.
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
 	}
+	defer tx.Rollback(ctx)
 
-	// Delete the secret from AWS Secrets Manager.
-	_, err = s.smClient.DeleteSecretWithContext(ctx, &secretsmanager.DeleteSecretInput{
-		SecretId:                   aws.String(ID),
-		ForceDeleteWithoutRecovery: aws.Bool(true),
-	})
+	// Delete connector from the database.
+	c, err := deleteConnectorRow(ctx, tx, ID, expectedVersion)
 	if err != nil {
+		return err
+	}
+
+	// Delete the secret from the secret store. A connector whose secret is gone already can
+	// still be deleted.
+	if c.SecretName != "" {
+		err = s.secretsIn(tx).DeleteSecret(ctx, c.SecretName)
+	}
+	if errors.Is(err, errs.ErrSecretNotFound) {
+		s.logger.Warn("Secret of deleted connector was missing", "connector-id", ID, "secret-name", c.SecretName)
+	} else if err != nil {
 		return fmt.Errorf("failed to delete secret for ID %s: %w", ID, err)
 	}
 
@@ -196,5 +306,54 @@ func (s *SqlStorage) DeleteConnector(ctx context.Context, ID string) error {
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
+	s.secretCommitted(c.SecretName)
 	return nil
 }
+
+// secretsIn returns the secret store changing secrets within tx, so that they commit or
+// roll back with the connector rows, when it is a TxSecretStore. Other stores change
+// secrets right away.
+func (s *SqlStorage) secretsIn(tx pgx.Tx) SecretStore {
+	return secretStoreIn(s.secrets, tx)
+}
+
+// uncachedSecretsIn is secretsIn bypassing the token cache, whose values may be stale
+// when the secrets are changed out of band.
+func (s *SqlStorage) uncachedSecretsIn(tx pgx.Tx) SecretStore {
+	store := s.secrets
+	if cache, ok := store.(*CachedSecretStore); ok {
+		store = cache.Uncached()
+	}
+	return secretStoreIn(store, tx)
+}
+
+func secretStoreIn(store SecretStore, tx pgx.Tx) SecretStore {
+	if txStore, ok := store.(TxSecretStore); ok {
+		return txStore.WithTx(tx)
+	}
+	return store
+}
+
+// secretCommitted drops a secret changed within a committed transaction from the token
+// cache, which a read racing with the transaction may have filled with the former value.
+func (s *SqlStorage) secretCommitted(name string) {
+	if cache, ok := s.secrets.(*CachedSecretStore); ok {
+		cache.Invalidate(name)
+	}
+}
+
+// deleteConnectorRow deletes a connector within tx and records the deletion. A non-zero
+// expectedVersion must be the connector's version.
+func deleteConnectorRow(ctx context.Context, tx pgx.Tx, connectorID string, expectedVersion int64) (*Connector, error) {
+	c, err := lockConnector(ctx, tx, connectorID, expectedVersion)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM connectors WHERE id = $1`, connectorID); err != nil {
+		return nil, fmt.Errorf("failed to delete connector with ID %s: %w", connectorID, err)
+	}
+	if err := recordAuditEvent(ctx, tx, AuditActionDelete, c, nil); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
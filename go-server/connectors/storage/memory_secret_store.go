@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"connector-recruitment/go-server/connectors/errs"
+)
+
+// MemorySecretStore keeps secrets in memory, for local development and tests. Like the
+// other stores, it keeps the current and the previous version of every secret.
+type MemorySecretStore struct {
+	mu      sync.Mutex
+	secrets map[string]*memorySecret
+}
+
+type memorySecret struct {
+	current   string
+	previous  *string
+	createdAt time.Time
+}
+
+// NewMemorySecretStore creates an empty MemorySecretStore.
+func NewMemorySecretStore() *MemorySecretStore {
+	return &MemorySecretStore{secrets: make(map[string]*memorySecret)}
+}
+
+// PutSecret stores the first version of a secret. Tags are not stored.
+func (s *MemorySecretStore) PutSecret(_ context.Context, name, value string, _ map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.secrets[name]; ok {
+		return errs.NewSecretAlreadyExistError(name)
+	}
+	s.secrets[name] = &memorySecret{current: value, createdAt: time.Now()}
+	return nil
+}
+
+func (s *MemorySecretStore) GetSecret(_ context.Context, name string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secret, ok := s.secrets[name]
+	if !ok {
+		return "", errs.NewSecretNotFoundError(name)
+	}
+	return secret.current, nil
+}
+
+func (s *MemorySecretStore) DeleteSecret(_ context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.secrets[name]; !ok {
+		return errs.NewSecretNotFoundError(name)
+	}
+	delete(s.secrets, name)
+	return nil
+}
+
+func (s *MemorySecretStore) RotateSecret(_ context.Context, name, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secret, ok := s.secrets[name]
+	if !ok {
+		return errs.NewSecretNotFoundError(name)
+	}
+	previous := secret.current
+	secret.previous = &previous
+	secret.current = value
+	return nil
+}
+
+func (s *MemorySecretStore) RollbackSecret(_ context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secret, ok := s.secrets[name]
+	if !ok {
+		return errs.NewSecretNotFoundError(name)
+	}
+	if secret.previous == nil {
+		return errs.NewNoPreviousSecretVersionError(name)
+	}
+	previous := secret.current
+	secret.current = *secret.previous
+	secret.previous = &previous
+	return nil
+}
+
+// ListSecrets returns every secret, by name.
+func (s *MemorySecretStore) ListSecrets(_ context.Context) ([]SecretInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secrets := make([]SecretInfo, 0, len(s.secrets))
+	for name, secret := range s.secrets {
+		secrets = append(secrets, SecretInfo{Name: name, CreatedAt: secret.createdAt})
+	}
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].Name < secrets[j].Name })
+	return secrets, nil
+}
@@ -0,0 +1,581 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	"connector-recruitment/go-server/connectors/audit"
+	"connector-recruitment/go-server/connectors/errs"
+	"connector-recruitment/go-server/connectors/logger"
+)
+
+// MemoryStorage is a Storage keeping everything in memory, for local development and
+// tests. It follows the semantics of SqlStorage: the same errors, orderings and versions,
+// and an audit event for every connector mutation. Nothing survives a restart.
+type MemoryStorage struct {
+	logger      logger.Logger
+	secrets     SecretStore
+	secretNamer SecretNamer
+
+	// connectorLocks serialize the mutations of a connector, as the row locks of SqlStorage
+	// do, so that s.mu need not be held while the secret store is called.
+	connectorLocks [64]sync.Mutex
+
+	mu           sync.RWMutex
+	tenants      map[string]*Tenant
+	connectors   map[string]*Connector
+	routingRules map[string]*RoutingRule
+	messages     []*MessageRecord
+	auditEvents  []*AuditEvent
+}
+
+// NewMemoryStorage creates an empty MemoryStorage. Connector tokens are kept in secrets.
+func NewMemoryStorage(secrets SecretStore, secretNamer SecretNamer, logger logger.Logger) *MemoryStorage {
+	return &MemoryStorage{
+		logger:       logger,
+		secrets:      secrets,
+		secretNamer:  secretNamer,
+		tenants:      make(map[string]*Tenant),
+		connectors:   make(map[string]*Connector),
+		routingRules: make(map[string]*RoutingRule),
+	}
+}
+
+func (s *MemoryStorage) SaveTenant(_ context.Context, tenant *Tenant) (*Tenant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.tenants[tenant.ID]; ok {
+		return nil, errs.NewTenantAlreadyExistError(tenant.ID)
+	}
+	now := time.Now()
+	saved := cloneTenant(tenant)
+	saved.Settings = nonNilLabels(saved.Settings)
+	saved.CreatedAt, saved.UpdatedAt = now, now
+	s.tenants[saved.ID] = saved
+	return cloneTenant(saved), nil
+}
+
+func (s *MemoryStorage) GetTenantByID(_ context.Context, tenantID string) (*Tenant, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	tenant, ok := s.tenants[tenantID]
+	if !ok {
+		return nil, errs.NewTenantNotFoundError(tenantID)
+	}
+	return cloneTenant(tenant), nil
+}
+
+// GetAllTenants retrieves every tenant, oldest first.
+func (s *MemoryStorage) GetAllTenants(_ context.Context) ([]*Tenant, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var tenants []*Tenant
+	for _, t := range s.tenants {
+		tenants = append(tenants, cloneTenant(t))
+	}
+	sort.Slice(tenants, func(i, j int) bool {
+		return createdBefore(tenants[i].CreatedAt, tenants[i].ID, tenants[j].CreatedAt, tenants[j].ID)
+	})
+	return tenants, nil
+}
+
+func (s *MemoryStorage) UpdateTenant(_ context.Context, tenant *Tenant, fields []TenantField) (*Tenant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.tenants[tenant.ID]
+	if !ok {
+		return nil, errs.NewTenantNotFoundError(tenant.ID)
+	}
+	if len(fields) == 0 {
+		return cloneTenant(stored), nil
+	}
+	updated := cloneTenant(stored)
+	for _, field := range fields {
+		switch field {
+		case TenantFieldName:
+			updated.Name = tenant.Name
+		case TenantFieldStatus:
+			updated.Status = tenant.Status
+		case TenantFieldSettings:
+			updated.Settings = nonNilLabels(cloneLabels(tenant.Settings))
+		default:
+			return nil, fmt.Errorf("unknown tenant field %q", field)
+		}
+	}
+	updated.UpdatedAt = time.Now()
+	s.tenants[tenant.ID] = updated
+	return cloneTenant(updated), nil
+}
+
+// SaveConnector stores a new connector and creates its secret in the secret store. The
+// connector is only stored once the secret is.
+func (s *MemoryStorage) SaveConnector(ctx context.Context, connector *Connector) (string, error) {
+	s.mu.RLock()
+	_, ok := s.tenants[connector.WorkspaceID]
+	s.mu.RUnlock()
+	if !ok {
+		return "", errs.NewTenantNotFoundError(connector.WorkspaceID)
+	}
+
+	saved := cloneConnector(connector)
+	saved.ID = newUUID()
+	saved.Token = ""
+	saved.Status = ConnectorStatusActive
+	saved.StatusReason = ""
+	saved.Health = ConnectorHealth{}
+	saved.Version = 1
+	saved.CreatedAt, saved.UpdatedAt = connector.creationTimes()
+	if connector.Token != "" {
+		saved.SecretName = s.secretNamer.Name(saved)
+		if err := s.secrets.PutSecret(ctx, saved.SecretName, connector.Token, s.secretNamer.Tags(saved)); err != nil {
+			return "", fmt.Errorf("failed to save connector secret: %w", err)
+		}
+		s.logger.Debug("Created secret", "secret-name", saved.SecretName)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connectors[saved.ID] = saved
+	s.recordAuditEvent(ctx, AuditActionCreate, nil, saved)
+	return saved.ID, nil
+}
+
+// GetConnectorByID retrieves a connector by its ID. The token is not fetched, see GetConnectorToken.
+func (s *MemoryStorage) GetConnectorByID(_ context.Context, connectorID string) (*Connector, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.connectors[connectorID]
+	if !ok {
+		return nil, errs.NewConnectorNotFoundError(connectorID)
+	}
+	return cloneConnector(c), nil
+}
+
+// GetConnectorToken fetches the secret token of a connector from the secret store. It is
+// empty when the connector has no secret.
+func (s *MemoryStorage) GetConnectorToken(ctx context.Context, connector *Connector) (string, error) {
+	if connector.SecretName == "" {
+		return "", nil
+	}
+	token, err := s.secrets.GetSecret(ctx, connector.SecretName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret value for connector %s: %w", connector.ID, err)
+	}
+	return token, nil
+}
+
+// GetAllConnectors retrieves all connectors, without their tokens, oldest first.
+func (s *MemoryStorage) GetAllConnectors(_ context.Context) ([]*Connector, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sortedConnectors(func(*Connector) bool { return true }), nil
+}
+
+// GetConnectorsByWorkspaceID retrieves every connector of a workspace, without their tokens.
+func (s *MemoryStorage) GetConnectorsByWorkspaceID(_ context.Context, workspaceID string) ([]*Connector, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sortedConnectors(func(c *Connector) bool { return c.WorkspaceID == workspaceID }), nil
+}
+
+func (s *MemoryStorage) sortedConnectors(keep func(*Connector) bool) []*Connector {
+	var connectors []*Connector
+	for _, c := range s.connectors {
+		if keep(c) {
+			connectors = append(connectors, cloneConnector(c))
+		}
+	}
+	sort.Slice(connectors, func(i, j int) bool {
+		return createdBefore(connectors[i].CreatedAt, connectors[i].ID, connectors[j].CreatedAt, connectors[j].ID)
+	})
+	return connectors
+}
+
+// RotateConnectorToken makes token the current version of the connector's secret. A
+// non-zero expectedVersion must be the connector's version.
+func (s *MemoryStorage) RotateConnectorToken(ctx context.Context, connectorID, token string, expectedVersion int64) error {
+	return s.changeSecret(ctx, AuditActionRotateToken, connectorID, expectedVersion, func(name string) error {
+		if err := s.secrets.RotateSecret(ctx, name, token); err != nil {
+			return fmt.Errorf("failed to rotate secret of connector %s: %w", connectorID, err)
+		}
+		return nil
+	})
+}
+
+// RollbackConnectorToken swaps the current and the previous versions of the connector's
+// secret. A non-zero expectedVersion must be the connector's version.
+func (s *MemoryStorage) RollbackConnectorToken(ctx context.Context, connectorID string, expectedVersion int64) error {
+	return s.changeSecret(ctx, AuditActionRollbackToken, connectorID, expectedVersion, func(name string) error {
+		if err := s.secrets.RollbackSecret(ctx, name); err != nil {
+			return fmt.Errorf("failed to roll back secret of connector %s: %w", connectorID, err)
+		}
+		return nil
+	})
+}
+
+// changeSecret runs change on the secret of a connector, then bumps the version and
+// updated_at of the connector and records the change, as SqlStorage does. Nothing is
+// recorded when change fails.
+func (s *MemoryStorage) changeSecret(ctx context.Context, action AuditAction, connectorID string, expectedVersion int64, change func(secretName string) error) error {
+	defer s.lockConnector(connectorID)()
+
+	s.mu.RLock()
+	c, err := s.checkedConnector(connectorID, expectedVersion)
+	var secretName string
+	if err == nil {
+		secretName = c.SecretName
+	}
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	if secretName == "" {
+		return errs.NewNoSecretError(connectorID)
+	}
+
+	if err := change(secretName); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// the connector lock kept the connector from changing meanwhile
+	c = s.connectors[connectorID]
+	before := cloneConnector(c)
+	c.UpdatedAt = time.Now()
+	c.Version++
+	s.recordAuditEvent(ctx, action, before, c)
+	return nil
+}
+
+// lockConnector locks the mutations of a connector until the returned func is called.
+func (s *MemoryStorage) lockConnector(connectorID string) (unlock func()) {
+	h := fnv.New32a()
+	h.Write([]byte(connectorID))
+	m := &s.connectorLocks[h.Sum32()%uint32(len(s.connectorLocks))]
+	m.Lock()
+	return m.Unlock
+}
+
+// checkedConnector returns the stored connector, whose version must be a non-zero
+// expectedVersion. s.mu must be held.
+func (s *MemoryStorage) checkedConnector(connectorID string, expectedVersion int64) (*Connector, error) {
+	c, ok := s.connectors[connectorID]
+	if !ok {
+		return nil, errs.NewConnectorNotFoundError(connectorID)
+	}
+	if expectedVersion != 0 && c.Version != expectedVersion {
+		return nil, errs.NewVersionMismatchError(connectorID, c.Version)
+	}
+	return c, nil
+}
+
+// SetConnectorStatus changes the status of a connector, along with the reason for it.
+func (s *MemoryStorage) SetConnectorStatus(ctx context.Context, connectorID string, status ConnectorStatus, reason string) error {
+	defer s.lockConnector(connectorID)()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.checkedConnector(connectorID, 0)
+	if err != nil {
+		return err
+	}
+	before := cloneConnector(c)
+	c.Status = status
+	c.StatusReason = reason
+	c.Version++
+	s.recordAuditEvent(ctx, AuditActionSetStatus, before, c)
+	return nil
+}
+
+// SaveConnectorHealth records the outcome of a health probe of a connector. It does not bump
+// the version.
+func (s *MemoryStorage) SaveConnectorHealth(_ context.Context, connectorID string, health ConnectorHealth) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.connectors[connectorID]
+	if !ok {
+		return errs.NewConnectorNotFoundError(connectorID)
+	}
+	c.Health = cloneHealth(health)
+	return nil
+}
+
+// DeleteConnector removes a connector and deletes its secret from the secret store. The
+// connector is kept when the secret cannot be deleted, unless it is missing already. A
+// non-zero expectedVersion must be the connector's version.
+func (s *MemoryStorage) DeleteConnector(ctx context.Context, ID string, expectedVersion int64) error {
+	defer s.lockConnector(ID)()
+
+	s.mu.RLock()
+	c, err := s.checkedConnector(ID, expectedVersion)
+	if err == nil {
+		c = cloneConnector(c)
+	}
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	if c.SecretName != "" {
+		err = s.secrets.DeleteSecret(ctx, c.SecretName)
+	}
+	if errors.Is(err, errs.ErrSecretNotFound) {
+		s.logger.Warn("Secret of deleted connector was missing", "connector-id", ID, "secret-name", c.SecretName)
+	} else if err != nil {
+		return fmt.Errorf("failed to delete secret for ID %s: %w", ID, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.connectors, ID)
+	s.recordAuditEvent(ctx, AuditActionDelete, c, nil)
+	return nil
+}
+
+func (s *MemoryStorage) SaveRoutingRule(_ context.Context, rule *RoutingRule) (*RoutingRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.tenants[rule.WorkspaceID]; !ok {
+		return nil, errs.NewTenantNotFoundError(rule.WorkspaceID)
+	}
+	now := time.Now()
+	saved := cloneRoutingRule(rule)
+	saved.ID = newUUID()
+	saved.MatchLabels = nonNilLabels(saved.MatchLabels)
+	saved.Targets = nonNilTargets(saved.Targets)
+	saved.CreatedAt, saved.UpdatedAt = now, now
+	s.routingRules[saved.ID] = saved
+	return cloneRoutingRule(saved), nil
+}
+
+func (s *MemoryStorage) GetRoutingRuleByID(_ context.Context, ruleID string) (*RoutingRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rule, ok := s.routingRules[ruleID]
+	if !ok {
+		return nil, errs.NewRoutingRuleNotFoundError(ruleID)
+	}
+	return cloneRoutingRule(rule), nil
+}
+
+// GetRoutingRulesByWorkspaceID retrieves the routing rules of a workspace in evaluation order.
+func (s *MemoryStorage) GetRoutingRulesByWorkspaceID(_ context.Context, workspaceID string) ([]*RoutingRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var rules []*RoutingRule
+	for _, r := range s.routingRules {
+		if r.WorkspaceID == workspaceID {
+			rules = append(rules, cloneRoutingRule(r))
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Priority != rules[j].Priority {
+			return rules[i].Priority > rules[j].Priority
+		}
+		return createdBefore(rules[i].CreatedAt, rules[i].ID, rules[j].CreatedAt, rules[j].ID)
+	})
+	return rules, nil
+}
+
+func (s *MemoryStorage) UpdateRoutingRule(_ context.Context, rule *RoutingRule) (*RoutingRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.routingRules[rule.ID]
+	if !ok {
+		return nil, errs.NewRoutingRuleNotFoundError(rule.ID)
+	}
+	stored.Name = rule.Name
+	stored.Priority = rule.Priority
+	stored.MatchLabels = nonNilLabels(cloneLabels(rule.MatchLabels))
+	stored.Targets = nonNilTargets(append([]RouteTarget(nil), rule.Targets...))
+	stored.UpdatedAt = time.Now()
+	return cloneRoutingRule(stored), nil
+}
+
+func (s *MemoryStorage) DeleteRoutingRule(_ context.Context, ruleID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.routingRules[ruleID]; !ok {
+		return errs.NewRoutingRuleNotFoundError(ruleID)
+	}
+	delete(s.routingRules, ruleID)
+	return nil
+}
+
+// SaveMessage records a send attempt and returns its ID.
+func (s *MemoryStorage) SaveMessage(_ context.Context, m *MessageRecord) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saved := *m
+	saved.ID = newUUID()
+	saved.Latency = saved.Latency.Truncate(time.Millisecond)
+	saved.CreatedAt = time.Now()
+	s.messages = append(s.messages, &saved)
+	return saved.ID, nil
+}
+
+// ListMessages returns the records matching the filter, newest first.
+func (s *MemoryStorage) ListMessages(_ context.Context, f MessageFilter) ([]*MessageRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var messages []*MessageRecord
+	for _, m := range s.messages {
+		if (f.ConnectorID == "" || m.ConnectorID == f.ConnectorID) &&
+			(f.WorkspaceID == "" || m.WorkspaceID == f.WorkspaceID) &&
+			(f.Status == "" || m.Status == f.Status) &&
+			inPage(m.CreatedAt, m.ID, f.From, f.To, f.After) {
+			record := *m
+			messages = append(messages, &record)
+		}
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return createdBefore(messages[j].CreatedAt, messages[j].ID, messages[i].CreatedAt, messages[i].ID)
+	})
+	if len(messages) > f.Limit {
+		messages = messages[:f.Limit]
+	}
+	return messages, nil
+}
+
+// ListAuditEvents returns the audit events matching the filter, newest first.
+func (s *MemoryStorage) ListAuditEvents(_ context.Context, f AuditFilter) ([]*AuditEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var events []*AuditEvent
+	for _, e := range s.auditEvents {
+		if (f.ConnectorID == "" || e.ConnectorID == f.ConnectorID) &&
+			(f.WorkspaceID == "" || e.WorkspaceID == f.WorkspaceID) &&
+			(f.Actor == "" || e.Actor == f.Actor) &&
+			inPage(e.CreatedAt, e.ID, f.From, f.To, f.After) {
+			event := *e
+			events = append(events, &event)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return createdBefore(events[j].CreatedAt, events[j].ID, events[i].CreatedAt, events[i].ID)
+	})
+	if len(events) > f.Limit {
+		events = events[:f.Limit]
+	}
+	return events, nil
+}
+
+// recordAuditEvent appends the change of a connector, made by the actor of ctx. The diff
+// cannot fail to encode the fields of a connector, so an error is only logged. s.mu must
+// be held.
+func (s *MemoryStorage) recordAuditEvent(ctx context.Context, action AuditAction, before, after *Connector) {
+	subject := after
+	if subject == nil {
+		subject = before
+	}
+	diff, err := connectorDiff(before, after)
+	if err != nil {
+		s.logger.Error("failed to record audit event", "connector-id", subject.ID, "err", err)
+		return
+	}
+	actor := audit.FromContext(ctx)
+	s.auditEvents = append(s.auditEvents, &AuditEvent{
+		ID:          newUUID(),
+		Actor:       actor.ID,
+		PeerAddress: actor.PeerAddress,
+		Method:      actor.Method,
+		Action:      action,
+		ConnectorID: subject.ID,
+		WorkspaceID: subject.WorkspaceID,
+		Diff:        diff,
+		CreatedAt:   time.Now(),
+	})
+}
+
+// inPage reports whether a record created at createdAt is within [from, to) and strictly
+// older than the cursor, zero bounds and a nil cursor being ignored.
+func inPage(createdAt time.Time, id string, from, to time.Time, after *MessageCursor) bool {
+	if !from.IsZero() && createdAt.Before(from) {
+		return false
+	}
+	if !to.IsZero() && !createdAt.Before(to) {
+		return false
+	}
+	return after == nil || createdBefore(createdAt, id, after.CreatedAt, after.ID)
+}
+
+// createdBefore orders records by (created_at, id), as the SQL queries do.
+func createdBefore(at time.Time, id string, otherAt time.Time, otherID string) bool {
+	if !at.Equal(otherAt) {
+		return at.Before(otherAt)
+	}
+	return id < otherID
+}
+
+// newUUID returns a random (version 4) UUID, like uuid_generate_v4.
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func cloneLabels(labels map[string]string) map[string]string {
+	if labels == nil {
+		return nil
+	}
+	clone := make(map[string]string, len(labels))
+	for k, v := range labels {
+		clone[k] = v
+	}
+	return clone
+}
+
+func cloneTenant(t *Tenant) *Tenant {
+	clone := *t
+	clone.Settings = cloneLabels(t.Settings)
+	return &clone
+}
+
+func cloneRoutingRule(r *RoutingRule) *RoutingRule {
+	clone := *r
+	clone.MatchLabels = cloneLabels(r.MatchLabels)
+	if r.Targets != nil {
+		clone.Targets = append([]RouteTarget{}, r.Targets...)
+	}
+	return &clone
+}
+
+func cloneHealth(h ConnectorHealth) ConnectorHealth {
+	if h.LastCheckedAt != nil {
+		at := *h.LastCheckedAt
+		h.LastCheckedAt = &at
+	}
+	if h.LastHealthyAt != nil {
+		at := *h.LastHealthyAt
+		h.LastHealthyAt = &at
+	}
+	return h
+}
+
+func cloneConnector(c *Connector) *Connector {
+	clone := *c
+	clone.Health = cloneHealth(c.Health)
+	if w := c.Settings.Webhook; w != nil {
+		webhook := *w
+		webhook.Headers = cloneLabels(w.Headers)
+		clone.Settings.Webhook = &webhook
+	}
+	if e := c.Settings.Email; e != nil {
+		email := *e
+		email.To = append([]string(nil), e.To...)
+		clone.Settings.Email = &email
+	}
+	if d := c.Settings.Digest; d != nil {
+		digest := *d
+		clone.Settings.Digest = &digest
+	}
+	return &clone
+}
@@ -0,0 +1,30 @@
+package storage_test
+
+import (
+	"log/slog"
+	"testing"
+
+	"connector-recruitment/go-server/connectors/storage"
+	"connector-recruitment/go-server/connectors/storage/storagetest"
+)
+
+func TestMemoryStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Storage {
+		return storage.NewMemoryStorage(storage.NewMemorySecretStore(), newSecretNamer(t), slog.Default())
+	})
+}
+
+func newSecretNamer(t *testing.T) storage.SecretNamer {
+	t.Helper()
+	namer, err := storage.NewSecretNamer("connectors/{tenant}/{id}", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return namer
+}
+
+func TestMemoryStorageDeleteConnectorWithoutSecret(t *testing.T) {
+	secrets := storage.NewMemorySecretStore()
+	s := storage.NewMemoryStorage(secrets, newSecretNamer(t), slog.Default())
+	storagetest.DeleteConnectorWithoutSecret(t, s, secrets)
+}
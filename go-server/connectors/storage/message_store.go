@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SaveMessage records a send attempt and returns its ID.
+func (s *SqlStorage) SaveMessage(ctx context.Context, m *MessageRecord) (messageID string, err error) {
+	query := `
+		INSERT INTO messages (connector_id, workspace_id, connector_type, channel, ts, status, status_code, error, latency_ms, content_hash) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) 
+		RETURNING id`
+	err = s.db.QueryRow(ctx, query,
+		m.ConnectorID,
+		m.WorkspaceID,
+		m.ConnectorType,
+		m.Channel,
+		m.TS,
+		m.Status,
+		m.StatusCode,
+		m.Error,
+		m.Latency.Milliseconds(),
+		m.ContentHash,
+	).Scan(&messageID)
+	if err != nil {
+		return "", fmt.Errorf("failed to save message: %w", err)
+	}
+	return messageID, nil
+}
+
+// ListMessages returns the records matching the filter, newest first.
+func (s *SqlStorage) ListMessages(ctx context.Context, f MessageFilter) ([]*MessageRecord, error) {
+	var (
+		conds []string
+		args  []any
+	)
+	where := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if f.ConnectorID != "" {
+		where("connector_id = $%d", f.ConnectorID)
+	}
+	if f.WorkspaceID != "" {
+		where("workspace_id = $%d", f.WorkspaceID)
+	}
+	if f.Status != "" {
+		where("status = $%d", f.Status)
+	}
+	if !f.From.IsZero() {
+		where("created_at >= $%d", f.From)
+	}
+	if !f.To.IsZero() {
+		where("created_at < $%d", f.To)
+	}
+	if f.After != nil {
+		args = append(args, f.After.CreatedAt, f.After.ID)
+		conds = append(conds, fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+
+	query := `
+		SELECT id, connector_id, workspace_id, connector_type, channel, ts, status, status_code, error, latency_ms, content_hash, created_at 
+		FROM messages`
+	if len(conds) > 0 {
+		query += "\n\t\tWHERE " + strings.Join(conds, " AND ")
+	}
+	args = append(args, f.Limit)
+	query += fmt.Sprintf("\n\t\tORDER BY created_at DESC, id DESC \n\t\tLIMIT $%d", len(args))
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*MessageRecord
+	for rows.Next() {
+		m := &MessageRecord{}
+		var latencyMs int64
+		err := rows.Scan(
+			&m.ID,
+			&m.ConnectorID,
+			&m.WorkspaceID,
+			&m.ConnectorType,
+			&m.Channel,
+			&m.TS,
+			&m.Status,
+			&m.StatusCode,
+			&m.Error,
+			&latencyMs,
+			&m.ContentHash,
+			&m.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan message row: %w", err)
+		}
+		m.Latency = time.Duration(latencyMs) * time.Millisecond
+		messages = append(messages, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return messages, nil
+}
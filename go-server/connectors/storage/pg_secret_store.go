@@ -0,0 +1,311 @@
+package storage
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"connector-recruitment/go-server/connectors/errs"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// KEKSize is the size in bytes of the key-encryption key of PostgresSecretStore (AES-256).
+const KEKSize = 32
+
+// pgUniqueViolation is the SQLSTATE of unique constraint violations.
+const pgUniqueViolation = "23505"
+
+// PostgresSecretStore stores secrets in the connector_secrets table using envelope
+// encryption: every version is sealed with AES-256-GCM under a random data key, and the
+// data key is sealed under the key-encryption key (KEK). Both are bound to the secret name,
+// so that a ciphertext copied to another row does not decrypt. Only the current and the
+// previous versions are kept.
+type PostgresSecretStore struct {
+	db  pgxConn
+	kek cipher.AEAD
+}
+
+// pgxConn runs the queries of a PostgresSecretStore: the pool, or a transaction.
+type pgxConn interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// NewPostgresSecretStore creates a SecretStore backed by Postgres. kek must be KEKSize bytes.
+func NewPostgresSecretStore(db *pgxpool.Pool, kek []byte) (*PostgresSecretStore, error) {
+	if len(kek) != KEKSize {
+		return nil, fmt.Errorf("key-encryption key must be %d bytes, got %d", KEKSize, len(kek))
+	}
+	aead, err := newGCM(kek)
+	if err != nil {
+		return nil, err
+	}
+	return &PostgresSecretStore{db: db, kek: aead}, nil
+}
+
+// WithTx returns the store running its queries within tx, so that its changes commit or
+// roll back with tx.
+func (s *PostgresSecretStore) WithTx(tx pgx.Tx) SecretStore {
+	return &PostgresSecretStore{db: tx, kek: s.kek}
+}
+
+// PutSecret stores the first version of a secret. Tags are not stored: the connectors
+// table already relates secrets to their connector and tenant.
+func (s *PostgresSecretStore) PutSecret(ctx context.Context, name, value string, _ map[string]string) error {
+	ciphertext, encryptedKey, err := s.encrypt(name, value)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO connector_secrets (name, version, ciphertext, encrypted_key)
+		VALUES ($1, 1, $2, $3)`
+	if _, err := s.db.Exec(ctx, query, name, ciphertext, encryptedKey); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return errs.NewSecretAlreadyExistError(name)
+		}
+		return fmt.Errorf("failed to save secret %s: %w", name, err)
+	}
+	return nil
+}
+
+func (s *PostgresSecretStore) GetSecret(ctx context.Context, name string) (string, error) {
+	var ciphertext, encryptedKey []byte
+	query := `
+		SELECT ciphertext, encrypted_key
+		FROM connector_secrets
+		WHERE name = $1
+		ORDER BY version DESC
+		LIMIT 1`
+	err := s.db.QueryRow(ctx, query, name).Scan(&ciphertext, &encryptedKey)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", errs.NewSecretNotFoundError(name)
+		}
+		return "", fmt.Errorf("failed to get secret %s: %w", name, err)
+	}
+	return s.decrypt(name, ciphertext, encryptedKey)
+}
+
+func (s *PostgresSecretStore) DeleteSecret(ctx context.Context, name string) error {
+	result, err := s.db.Exec(ctx, `DELETE FROM connector_secrets WHERE name = $1`, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete secret %s: %w", name, err)
+	}
+	if result.RowsAffected() == 0 {
+		return errs.NewSecretNotFoundError(name)
+	}
+	return nil
+}
+
+// RotateSecret adds a version and drops the versions older than the one it replaces. The
+// current version is locked, so that concurrent rotations of a secret are serialized.
+func (s *PostgresSecretStore) RotateSecret(ctx context.Context, name, value string) (err error) {
+	ciphertext, encryptedKey, err := s.encrypt(name, value)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	var current int
+	query := `
+		SELECT version
+		FROM connector_secrets
+		WHERE name = $1
+		ORDER BY version DESC
+		LIMIT 1
+		FOR UPDATE`
+	if err = tx.QueryRow(ctx, query, name).Scan(&current); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errs.NewSecretNotFoundError(name)
+		}
+		return fmt.Errorf("failed to get secret %s: %w", name, err)
+	}
+
+	query = `
+		INSERT INTO connector_secrets (name, version, ciphertext, encrypted_key)
+		VALUES ($1, $2, $3, $4)`
+	if _, err = tx.Exec(ctx, query, name, current+1, ciphertext, encryptedKey); err != nil {
+		return fmt.Errorf("failed to save secret %s: %w", name, err)
+	}
+	if _, err = tx.Exec(ctx, `DELETE FROM connector_secrets WHERE name = $1 AND version < $2`, name, current); err != nil {
+		return fmt.Errorf("failed to prune versions of secret %s: %w", name, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// RollbackSecret makes a copy of the previous version the current one. The version it
+// replaces becomes the previous one, as with RotateSecret.
+func (s *PostgresSecretStore) RollbackSecret(ctx context.Context, name string) (err error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	rows, err := tx.Query(ctx, `
+		SELECT version, ciphertext, encrypted_key
+		FROM connector_secrets
+		WHERE name = $1
+		ORDER BY version DESC
+		LIMIT 2
+		FOR UPDATE`, name)
+	if err != nil {
+		return fmt.Errorf("failed to get secret %s: %w", name, err)
+	}
+	type secretVersion struct {
+		version                  int
+		ciphertext, encryptedKey []byte
+	}
+	var versions []secretVersion
+	for rows.Next() {
+		var v secretVersion
+		if err = rows.Scan(&v.version, &v.ciphertext, &v.encryptedKey); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan secret %s: %w", name, err)
+		}
+		versions = append(versions, v)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("failed to get secret %s: %w", name, err)
+	}
+	switch len(versions) {
+	case 0:
+		return errs.NewSecretNotFoundError(name)
+	case 1:
+		return errs.NewNoPreviousSecretVersionError(name)
+	}
+
+	current, previous := versions[0], versions[1]
+	query := `
+		INSERT INTO connector_secrets (name, version, ciphertext, encrypted_key)
+		VALUES ($1, $2, $3, $4)`
+	if _, err = tx.Exec(ctx, query, name, current.version+1, previous.ciphertext, previous.encryptedKey); err != nil {
+		return fmt.Errorf("failed to save secret %s: %w", name, err)
+	}
+	if _, err = tx.Exec(ctx, `DELETE FROM connector_secrets WHERE name = $1 AND version < $2`, name, current.version); err != nil {
+		return fmt.Errorf("failed to prune versions of secret %s: %w", name, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresSecretStore) ListSecrets(ctx context.Context) ([]SecretInfo, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT name, MIN(created_at)
+		FROM connector_secrets
+		GROUP BY name
+		ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	defer rows.Close()
+
+	var secrets []SecretInfo
+	for rows.Next() {
+		var info SecretInfo
+		if err := rows.Scan(&info.Name, &info.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan secret row: %w", err)
+		}
+		secrets = append(secrets, info)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return secrets, nil
+}
+
+// encrypt seals value under a new data key and the data key under the KEK.
+func (s *PostgresSecretStore) encrypt(name, value string) (ciphertext, encryptedKey []byte, err error) {
+	dataKey := make([]byte, KEKSize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if ciphertext, err = seal(aead, []byte(value), name); err != nil {
+		return nil, nil, err
+	}
+	if encryptedKey, err = seal(s.kek, dataKey, name); err != nil {
+		return nil, nil, err
+	}
+	return ciphertext, encryptedKey, nil
+}
+
+func (s *PostgresSecretStore) decrypt(name string, ciphertext, encryptedKey []byte) (string, error) {
+	dataKey, err := unseal(s.kek, encryptedKey, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt data key of secret %s: %w", name, err)
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return "", err
+	}
+	value, err := unseal(aead, ciphertext, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret %s: %w", name, err)
+	}
+	return string(value), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return aead, nil
+}
+
+// seal encrypts plaintext with a random nonce, which is prepended to the result.
+func seal(aead cipher.AEAD, plaintext []byte, additionalData string) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, []byte(additionalData)), nil
+}
+
+func unseal(aead cipher.AEAD, sealed []byte, additionalData string) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, []byte(additionalData))
+}
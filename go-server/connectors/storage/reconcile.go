@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"connector-recruitment/go-server/connectors/errs"
+)
+
+// DefaultReconcileMinAge is how old a secret without connector, or a connector without
+// secret, must be by default to count as an orphan. Without a TxSecretStore, SaveConnector
+// creates the secret before committing the connector row, so a younger secret or row may
+// belong to a connector being created.
+const DefaultReconcileMinAge = 15 * time.Minute
+
+// orphanConnectorReason is the status reason of the connectors suspended by Reconcile.
+const orphanConnectorReason = "secret missing, found by reconciliation"
+
+// uuidPattern matches the connector IDs.
+const uuidPattern = `[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`
+
+// connectorSecretName matches the legacy names of connector secrets: the connector UUID.
+var connectorSecretName = regexp.MustCompile(`^` + uuidPattern + `$`)
+
+// ReconcileReport lists the secrets without connector row and the connector rows without
+// secret. With repair, the orphans that were repaired are listed in Repaired, and those
+// that could not be in Errors.
+type ReconcileReport struct {
+	OrphanSecrets    []string
+	OrphanConnectors []string
+	Repaired         []string
+	Errors           []string
+}
+
+// Reconcile compares the secrets of the secret store with the connectors table. Only the
+// secrets in the namespace of the SecretNamer can be orphans; a connector row is one when
+// no secret has its secret name. Secrets and rows younger than minAge are skipped.
+// Unless the secret store is a TxSecretStore, SaveConnector and DeleteConnector change both
+// without a shared transaction, so a crash or a failed commit leaves a secret without row,
+// or a row without secret. With repair, orphan secrets are deleted, and orphan rows are
+// suspended rather than deleted: a connector without secret cannot send, and its owner can
+// still see why and delete it. Every orphan is checked again right before it is repaired.
+func (s *SqlStorage) Reconcile(ctx context.Context, repair bool, minAge time.Duration) (*ReconcileReport, error) {
+	connectors, err := s.connectorSecretNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	secrets, err := s.secrets.ListSecrets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReconcileReport{}
+	secretNames := make(map[string]bool, len(secrets))
+	for _, secret := range secrets {
+		// a connector may use a secret out of the namespace, named under a former template
+		secretNames[secret.Name] = true
+		if !s.secretNamer.InNamespace(secret.Name) {
+			continue
+		}
+		if _, ok := connectors[secret.Name]; !ok && time.Since(secret.CreatedAt) > minAge {
+			report.OrphanSecrets = append(report.OrphanSecrets, secret.Name)
+		}
+	}
+	orphanConnectors := make(map[string]string)
+	for secretName, c := range connectors {
+		if !secretNames[secretName] && time.Since(c.createdAt) > minAge {
+			report.OrphanConnectors = append(report.OrphanConnectors, c.id)
+			orphanConnectors[c.id] = secretName
+		}
+	}
+
+	if !repair {
+		return report, nil
+	}
+	for _, name := range report.OrphanSecrets {
+		if err := s.deleteOrphanSecret(ctx, name); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("secret %s: %v", name, err))
+			continue
+		}
+		report.Repaired = append(report.Repaired, name)
+	}
+	for _, id := range report.OrphanConnectors {
+		if err := s.suspendOrphanConnector(ctx, id, orphanConnectors[id]); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("connector %s: %v", id, err))
+			continue
+		}
+		report.Repaired = append(report.Repaired, id)
+	}
+	return report, nil
+}
+
+// secretOwner is the connector using a secret.
+type secretOwner struct {
+	id        string
+	createdAt time.Time
+}
+
+// connectorSecretNames maps the secret names of the connectors to them. Connectors without
+// secret are left out.
+func (s *SqlStorage) connectorSecretNames(ctx context.Context) (map[string]secretOwner, error) {
+	rows, err := s.db.Query(ctx, `SELECT id, secret_name, created_at FROM connectors WHERE secret_name <> ''`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query connectors: %w", err)
+	}
+	defer rows.Close()
+
+	names := make(map[string]secretOwner)
+	for rows.Next() {
+		var (
+			secretName string
+			c          secretOwner
+		)
+		if err := rows.Scan(&c.id, &secretName, &c.createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		names[secretName] = c
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return names, nil
+}
+
+func (s *SqlStorage) deleteOrphanSecret(ctx context.Context, name string) error {
+	var exists bool
+	if err := s.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM connectors WHERE secret_name = $1)`, name).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check connector: %w", err)
+	}
+	if exists {
+		return errors.New("connector row appeared, skipped")
+	}
+	if err := s.secrets.DeleteSecret(ctx, name); err != nil && !errors.Is(err, errs.ErrSecretNotFound) {
+		return err
+	}
+	s.logger.Warn("Deleted orphan secret", "secret-name", name)
+	return nil
+}
+
+// suspendOrphanConnector suspends a connector without secret, unless it got one or
+// another secret name since it was found, or is suspended for it already.
+func (s *SqlStorage) suspendOrphanConnector(ctx context.Context, id, secretName string) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	c, err := lockConnector(ctx, tx, id, 0)
+	if err != nil {
+		return err
+	}
+	if c.SecretName != secretName {
+		return errors.New("secret renamed, skipped")
+	}
+	if c.Status == ConnectorStatusSuspended && c.StatusReason == orphanConnectorReason {
+		return nil
+	}
+	// the cache may still hold a secret deleted out of band, the very case to repair
+	if _, err := s.uncachedSecretsIn(tx).GetSecret(ctx, secretName); err == nil {
+		return errors.New("secret appeared, skipped")
+	} else if !errors.Is(err, errs.ErrSecretNotFound) {
+		return err
+	}
+	if _, err := updateLockedConnector(ctx, tx, AuditActionSetStatus, c,
+		`status = $2, status_reason = $3`, ConnectorStatusSuspended, orphanConnectorReason); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	s.logger.Warn("Suspended connector without secret", "connector-id", id)
+	return nil
+}
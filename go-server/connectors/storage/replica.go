@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Replica is a read replica of the database.
+type Replica interface {
+	// Pool returns the replica pool, or nil when reads must go to the primary.
+	Pool() *pgxpool.Pool
+}
+
+type replicaReadsKey struct{}
+
+// WithReplicaReads allows the connector reads made with ctx to be served by the replica,
+// which may lag behind the primary. Without it, every query goes to the primary, so that
+// callers about to write, or who just wrote, read the latest state.
+func WithReplicaReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, replicaReadsKey{}, true)
+}
+
+// reader returns the pool to read connectors from with ctx.
+func (s *SqlStorage) reader(ctx context.Context) *pgxpool.Pool {
+	if s.replica == nil {
+		return s.db
+	}
+	if allowed, _ := ctx.Value(replicaReadsKey{}).(bool); !allowed {
+		return s.db
+	}
+	if pool := s.replica.Pool(); pool != nil {
+		return pool
+	}
+	return s.db
+}
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"connector-recruitment/go-server/connectors/errs"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const routingRuleColumns = `id, workspace_id, name, priority, match_labels, targets, created_at, updated_at`
+
+// scanRoutingRule scans a single routing_rules row into a RoutingRule struct.
+func scanRoutingRule(row pgx.Row) (*RoutingRule, error) {
+	r := &RoutingRule{}
+	err := row.Scan(
+		&r.ID,
+		&r.WorkspaceID,
+		&r.Name,
+		&r.Priority,
+		&r.MatchLabels,
+		&r.Targets,
+		&r.CreatedAt,
+		&r.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// SaveRoutingRule inserts a new routing rule and returns it as stored. The tenant of the
+// rule must exist.
+func (s *SqlStorage) SaveRoutingRule(ctx context.Context, rule *RoutingRule) (*RoutingRule, error) {
+	query := `
+		INSERT INTO routing_rules (workspace_id, name, priority, match_labels, targets) 
+		VALUES ($1, $2, $3, $4, $5) 
+		RETURNING ` + routingRuleColumns
+	saved, err := scanRoutingRule(s.db.QueryRow(ctx, query,
+		rule.WorkspaceID,
+		rule.Name,
+		rule.Priority,
+		nonNilLabels(rule.MatchLabels),
+		nonNilTargets(rule.Targets),
+	))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+			return nil, errs.NewTenantNotFoundError(rule.WorkspaceID)
+		}
+		return nil, fmt.Errorf("failed to save routing rule: %w", err)
+	}
+	return saved, nil
+}
+
+// GetRoutingRuleByID retrieves a routing rule by its ID.
+func (s *SqlStorage) GetRoutingRuleByID(ctx context.Context, ruleID string) (*RoutingRule, error) {
+	query := `SELECT ` + routingRuleColumns + ` FROM routing_rules WHERE id = $1`
+	rule, err := scanRoutingRule(s.db.QueryRow(ctx, query, ruleID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NewRoutingRuleNotFoundError(ruleID)
+		}
+		return nil, fmt.Errorf("failed to get routing rule by ID: %w", err)
+	}
+	return rule, nil
+}
+
+// GetRoutingRulesByWorkspaceID retrieves the routing rules of a workspace in evaluation order.
+func (s *SqlStorage) GetRoutingRulesByWorkspaceID(ctx context.Context, workspaceID string) ([]*RoutingRule, error) {
+	query := `
+		SELECT ` + routingRuleColumns + ` 
+		FROM routing_rules 
+		WHERE workspace_id = $1 
+		ORDER BY priority DESC, created_at, id`
+	rows, err := s.db.Query(ctx, query, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query routing rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*RoutingRule
+	for rows.Next() {
+		r, err := scanRoutingRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan routing rule row: %w", err)
+		}
+		rules = append(rules, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return rules, nil
+}
+
+// UpdateRoutingRule replaces the name, priority, selector and targets of an existing rule.
+func (s *SqlStorage) UpdateRoutingRule(ctx context.Context, rule *RoutingRule) (*RoutingRule, error) {
+	query := `
+		UPDATE routing_rules 
+		SET name = $2, priority = $3, match_labels = $4, targets = $5 
+		WHERE id = $1 
+		RETURNING ` + routingRuleColumns
+	updated, err := scanRoutingRule(s.db.QueryRow(ctx, query,
+		rule.ID,
+		rule.Name,
+		rule.Priority,
+		nonNilLabels(rule.MatchLabels),
+		nonNilTargets(rule.Targets),
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NewRoutingRuleNotFoundError(rule.ID)
+		}
+		return nil, fmt.Errorf("failed to update routing rule: %w", err)
+	}
+	return updated, nil
+}
+
+// DeleteRoutingRule removes a routing rule by ID.
+func (s *SqlStorage) DeleteRoutingRule(ctx context.Context, ruleID string) error {
+	result, err := s.db.Exec(ctx, `DELETE FROM routing_rules WHERE id = $1`, ruleID)
+	if err != nil {
+		return fmt.Errorf("failed to delete routing rule with ID %s: %w", ruleID, err)
+	}
+	if result.RowsAffected() == 0 {
+		return errs.NewRoutingRuleNotFoundError(ruleID)
+	}
+	return nil
+}
+
+// nonNilLabels keeps empty selectors stored as {} rather than null.
+func nonNilLabels(labels map[string]string) map[string]string {
+	if labels == nil {
+		return map[string]string{}
+	}
+	return labels
+}
+
+// nonNilTargets keeps empty target lists stored as [] rather than null.
+func nonNilTargets(targets []RouteTarget) []RouteTarget {
+	if targets == nil {
+		return []RouteTarget{}
+	}
+	return targets
+}
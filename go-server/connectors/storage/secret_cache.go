@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CachedSecretStore keeps the values read from a SecretStore in memory, so that sending a
+// message does not call the secret store every time. It holds at most size secrets, evicting
+// the least recently used, each for at most ttl. Writes through the cache invalidate the
+// secret; writes made directly to the underlying store are only seen once the TTL expired.
+type CachedSecretStore struct {
+	store SecretStore
+	size  int
+	ttl   time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // most recently used first
+	// generation is incremented by every invalidation. A value read from the store is only
+	// cached when no invalidation happened during the read, so that a read racing with a
+	// rotation cannot cache the replaced value.
+	generation uint64
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+type cacheEntry struct {
+	name    string
+	value   string
+	expires time.Time
+}
+
+// CacheStats are the counters of a CachedSecretStore since its creation.
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Size      int
+}
+
+// NewCachedSecretStore wraps store with a cache of at most size secrets kept for ttl.
+func NewCachedSecretStore(store SecretStore, size int, ttl time.Duration) *CachedSecretStore {
+	return &CachedSecretStore{
+		store:   store,
+		size:    max(size, 1),
+		ttl:     ttl,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+func (c *CachedSecretStore) GetSecret(ctx context.Context, name string) (string, error) {
+	c.mu.Lock()
+	if el, ok := c.entries[name]; ok {
+		entry := el.Value.(*cacheEntry)
+		if time.Now().Before(entry.expires) {
+			c.lru.MoveToFront(el)
+			c.mu.Unlock()
+			c.hits.Add(1)
+			return entry.value, nil
+		}
+		c.remove(el)
+	}
+	generation := c.generation
+	c.mu.Unlock()
+	c.misses.Add(1)
+
+	value, err := c.store.GetSecret(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation == generation {
+		c.add(name, value)
+	}
+	return value, nil
+}
+
+func (c *CachedSecretStore) PutSecret(ctx context.Context, name, value string, tags map[string]string) error {
+	defer c.Invalidate(name)
+	return c.store.PutSecret(ctx, name, value, tags)
+}
+
+func (c *CachedSecretStore) DeleteSecret(ctx context.Context, name string) error {
+	defer c.Invalidate(name)
+	return c.store.DeleteSecret(ctx, name)
+}
+
+func (c *CachedSecretStore) RotateSecret(ctx context.Context, name, value string) error {
+	defer c.Invalidate(name)
+	return c.store.RotateSecret(ctx, name, value)
+}
+
+func (c *CachedSecretStore) RollbackSecret(ctx context.Context, name string) error {
+	defer c.Invalidate(name)
+	return c.store.RollbackSecret(ctx, name)
+}
+
+func (c *CachedSecretStore) ListSecrets(ctx context.Context) ([]SecretInfo, error) {
+	return c.store.ListSecrets(ctx)
+}
+
+// WithTx returns the cache over the store within tx, when the store is a TxSecretStore, and
+// the cache itself otherwise. Reads within tx bypass the cache, and changes invalidate it;
+// since a read racing with tx may still cache a value tx replaces, the changed secrets
+// must be invalidated again once tx is committed.
+func (c *CachedSecretStore) WithTx(tx pgx.Tx) SecretStore {
+	store, ok := c.store.(TxSecretStore)
+	if !ok {
+		return c
+	}
+	return &cachedTxSecretStore{cache: c, store: store.WithTx(tx)}
+}
+
+// Uncached returns the underlying store, for the reads that must not see a value the
+// cache still holds for a secret changed out of band.
+func (c *CachedSecretStore) Uncached() SecretStore {
+	return c.store
+}
+
+// Invalidate drops the cached value of a secret.
+func (c *CachedSecretStore) Invalidate(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	if el, ok := c.entries[name]; ok {
+		c.remove(el)
+	}
+}
+
+// Stats returns the cache counters.
+func (c *CachedSecretStore) Stats() CacheStats {
+	c.mu.Lock()
+	size := c.lru.Len()
+	c.mu.Unlock()
+	return CacheStats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Size:      size,
+	}
+}
+
+// add caches a value, evicting the least recently used entries over size. c.mu must be held.
+func (c *CachedSecretStore) add(name, value string) {
+	if el, ok := c.entries[name]; ok {
+		c.remove(el)
+	}
+	c.entries[name] = c.lru.PushFront(&cacheEntry{name: name, value: value, expires: time.Now().Add(c.ttl)})
+	for c.lru.Len() > c.size {
+		c.remove(c.lru.Back())
+		c.evictions.Add(1)
+	}
+}
+
+// remove drops an entry. c.mu must be held.
+func (c *CachedSecretStore) remove(el *list.Element) {
+	c.lru.Remove(el)
+	delete(c.entries, el.Value.(*cacheEntry).name)
+}
+
+// cachedTxSecretStore is a CachedSecretStore within a transaction.
+type cachedTxSecretStore struct {
+	cache *CachedSecretStore
+	store SecretStore
+}
+
+func (c *cachedTxSecretStore) GetSecret(ctx context.Context, name string) (string, error) {
+	return c.store.GetSecret(ctx, name)
+}
+
+func (c *cachedTxSecretStore) PutSecret(ctx context.Context, name, value string, tags map[string]string) error {
+	defer c.cache.Invalidate(name)
+	return c.store.PutSecret(ctx, name, value, tags)
+}
+
+func (c *cachedTxSecretStore) DeleteSecret(ctx context.Context, name string) error {
+	defer c.cache.Invalidate(name)
+	return c.store.DeleteSecret(ctx, name)
+}
+
+func (c *cachedTxSecretStore) RotateSecret(ctx context.Context, name, value string) error {
+	defer c.cache.Invalidate(name)
+	return c.store.RotateSecret(ctx, name, value)
+}
+
+func (c *cachedTxSecretStore) RollbackSecret(ctx context.Context, name string) error {
+	defer c.cache.Invalidate(name)
+	return c.store.RollbackSecret(ctx, name)
+}
+
+func (c *cachedTxSecretStore) ListSecrets(ctx context.Context) ([]SecretInfo, error) {
+	return c.store.ListSecrets(ctx)
+}
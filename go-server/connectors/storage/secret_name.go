@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultSecretNameTemplate is the secret name template used when none is configured.
+const DefaultSecretNameTemplate = "connectors/{tenant}/{id}"
+
+// Secret tag keys.
+const (
+	SecretTagTenant      = "tenant"
+	SecretTagConnector   = "connector"
+	SecretTagEnvironment = "environment"
+)
+
+// SecretNamer names and tags the secrets of connectors. The template may use {tenant},
+// {id} and {env}, replaced by the connector's workspace ID, the connector ID and the
+// environment. The name of a connector's secret is stored with the connector, so that
+// changing the template only affects new connectors until their secrets are renamed.
+type SecretNamer struct {
+	template    string
+	environment string
+	// namespace matches the names the template gives.
+	namespace *regexp.Regexp
+}
+
+// NewSecretNamer validates the template: it must contain {id}, so that every connector
+// gets its own secret.
+func NewSecretNamer(template, environment string) (SecretNamer, error) {
+	if template == "" {
+		template = DefaultSecretNameTemplate
+	}
+	if !strings.Contains(template, "{id}") {
+		return SecretNamer{}, fmt.Errorf("secret name template %q must contain {id}", template)
+	}
+	rest := strings.NewReplacer("{tenant}", "", "{id}", "", "{env}", "").Replace(template)
+	if strings.ContainsAny(rest, "{}") {
+		return SecretNamer{}, fmt.Errorf("secret name template %q has an unknown placeholder", template)
+	}
+	namespace := strings.NewReplacer(
+		`\{tenant\}`, `.+`,
+		`\{id\}`, uuidPattern,
+		`\{env\}`, regexp.QuoteMeta(environment),
+	).Replace(regexp.QuoteMeta(template))
+	return SecretNamer{
+		template:    template,
+		environment: environment,
+		namespace:   regexp.MustCompile("^" + namespace + "$"),
+	}, nil
+}
+
+// Name returns the name the connector's secret gets under the template.
+func (n SecretNamer) Name(c *Connector) string {
+	return strings.NewReplacer(
+		"{tenant}", c.WorkspaceID,
+		"{id}", c.ID,
+		"{env}", n.environment,
+	).Replace(n.template)
+}
+
+// Tags returns the tags of the connector's secret.
+func (n SecretNamer) Tags(c *Connector) map[string]string {
+	tags := map[string]string{
+		SecretTagTenant:    c.WorkspaceID,
+		SecretTagConnector: c.ID,
+	}
+	if n.environment != "" {
+		tags[SecretTagEnvironment] = n.environment
+	}
+	return tags
+}
+
+// InNamespace reports whether a secret may belong to a connector: it is a name the template
+// gives, or the legacy name, the bare connector UUID.
+func (n SecretNamer) InNamespace(name string) bool {
+	return connectorSecretName.MatchString(name) || n.namespace.MatchString(name)
+}
@@ -0,0 +1,36 @@
+package storage
+
+import "testing"
+
+func TestSecretNamerInNamespace(t *testing.T) {
+	const id = "0b6f3f1e-2c4d-4e5f-8a9b-0c1d2e3f4a5b"
+	tests := []struct {
+		template string
+		name     string
+		want     bool
+	}{
+		{"connectors/{tenant}/{id}", "connectors/acme/" + id, true},
+		{"connectors/{tenant}/{id}", "connectors/acme/not-an-id", false},
+		{"connectors/{tenant}/{id}", "other/acme/" + id, false},
+		{"{env}/connectors/{id}", "prod/connectors/" + id, true},
+		{"{env}/connectors/{id}", "staging/connectors/" + id, false},
+		{"{tenant}-{id}", "acme-" + id, true},
+		{"{tenant}-{id}", "acme-" + id + "-copy", false},
+		{"svc.{id}", "svcx" + id, false},
+		// the legacy name, whatever the template
+		{"{env}/connectors/{id}", id, true},
+	}
+	for _, tt := range tests {
+		namer, err := NewSecretNamer(tt.template, "prod")
+		if err != nil {
+			t.Fatalf("NewSecretNamer(%q): %v", tt.template, err)
+		}
+		if got := namer.InNamespace(tt.name); got != tt.want {
+			t.Errorf("InNamespace(%q) with template %q = %v, want %v", tt.name, tt.template, got, tt.want)
+		}
+		c := &Connector{ID: id, WorkspaceID: "acme"}
+		if name := namer.Name(c); !namer.InNamespace(name) {
+			t.Errorf("InNamespace(%q) = false for a name given by template %q", name, tt.template)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"connector-recruitment/go-server/connectors/errs"
+)
+
+// SecretRename is a connector secret whose name does not follow the SecretNamer.
+type SecretRename struct {
+	ConnectorID string
+	From        string
+	To          string
+	Err         error
+}
+
+// RenameConnectorSecrets moves the secrets of the connectors to the names the SecretNamer
+// gives them, tagging them on the way. Only the current version of a secret is copied, so
+// a renamed connector cannot roll back its token. With dryRun, nothing is changed.
+// A failed rename is reported in its SecretRename and does not stop the others.
+// Connectors without secret are skipped.
+func (s *SqlStorage) RenameConnectorSecrets(ctx context.Context, dryRun bool) ([]SecretRename, error) {
+	connectors, err := s.GetAllConnectors(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var renames []SecretRename
+	for _, c := range connectors {
+		rename := SecretRename{ConnectorID: c.ID, From: c.SecretName, To: s.secretNamer.Name(c)}
+		if rename.From == rename.To || rename.From == "" {
+			continue
+		}
+		if !dryRun {
+			rename.Err = s.renameConnectorSecret(ctx, c, rename.To)
+		}
+		renames = append(renames, rename)
+	}
+	return renames, nil
+}
+
+// renameConnectorSecret copies the secret before pointing the connector at it, and only then
+// deletes the old one, so the connector always has a readable secret. The connector row is
+// locked during the copy, so that a concurrent rotation waits for the rename and then
+// rotates the new secret.
+func (s *SqlStorage) renameConnectorSecret(ctx context.Context, c *Connector, name string) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Every change of secret_name bumps the version, so the version guards the old name.
+	before, err := lockConnector(ctx, tx, c.ID, c.Version)
+	if errors.Is(err, errs.ErrVersionMismatch) {
+		return errors.New("connector changed, skipped")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock connector: %w", err)
+	}
+
+	secrets := s.secretsIn(tx)
+	value, err := secrets.GetSecret(ctx, before.SecretName)
+	if err != nil {
+		return fmt.Errorf("failed to read secret: %w", err)
+	}
+	err = secrets.PutSecret(ctx, name, value, s.secretNamer.Tags(before))
+	if err != nil && !errors.Is(err, errs.ErrSecretExistAlready) {
+		return fmt.Errorf("failed to create secret: %w", err)
+	}
+	if errors.Is(err, errs.ErrSecretExistAlready) {
+		// Left over by an interrupted rename: make sure it holds the current value.
+		if err := secrets.RotateSecret(ctx, name, value); err != nil {
+			return fmt.Errorf("failed to update secret: %w", err)
+		}
+	}
+
+	if _, err := updateLockedConnector(ctx, tx, AuditActionRenameSecret, before, `secret_name = $2, updated_at = NOW()`, name); err != nil {
+		return fmt.Errorf("failed to update connector: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if err := s.secrets.DeleteSecret(ctx, before.SecretName); err != nil && !errors.Is(err, errs.ErrSecretNotFound) {
+		return fmt.Errorf("failed to delete old secret: %w", err)
+	}
+	s.logger.Info("Renamed connector secret", "connector-id", c.ID, "from", before.SecretName, "to", name)
+	return nil
+}
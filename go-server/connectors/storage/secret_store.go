@@ -0,0 +1,198 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"connector-recruitment/go-server/connectors/errs"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/jackc/pgx/v5"
+)
+
+// SecretStore keeps the connectors' secrets out of the connectors table. Secrets are
+// identified by name and versioned: RotateSecret adds a version, and GetSecret returns the
+// latest one.
+type SecretStore interface {
+	// PutSecret creates a secret. It fails with errs.ErrSecretExistAlready when the name is
+	// taken. Stores without tagging support ignore tags.
+	PutSecret(ctx context.Context, name, value string, tags map[string]string) error
+	// GetSecret returns the current value of a secret, or errs.ErrSecretNotFound.
+	GetSecret(ctx context.Context, name string) (string, error)
+	// DeleteSecret deletes a secret with all of its versions, or fails with errs.ErrSecretNotFound.
+	DeleteSecret(ctx context.Context, name string) error
+	// RotateSecret makes value the current version of an existing secret. The version it
+	// replaces is kept as the previous one.
+	RotateSecret(ctx context.Context, name, value string) error
+	// RollbackSecret swaps the current and the previous versions of a secret, so that a
+	// second rollback undoes the first. It fails with errs.ErrNoPreviousSecretVersion when
+	// the secret has a single version.
+	RollbackSecret(ctx context.Context, name string) error
+	// ListSecrets returns every secret of the store.
+	ListSecrets(ctx context.Context) ([]SecretInfo, error)
+}
+
+// TxSecretStore is a SecretStore kept in the database of the connectors. Its changes can
+// join the transaction changing a connector, so that the connector and its secret commit
+// or roll back together.
+type TxSecretStore interface {
+	SecretStore
+	// WithTx returns the store reading and changing secrets within tx.
+	WithTx(tx pgx.Tx) SecretStore
+}
+
+// SecretInfo describes a secret without its value.
+type SecretInfo struct {
+	Name      string
+	CreatedAt time.Time
+}
+
+// AWSSecretStore stores secrets in AWS Secrets Manager.
+type AWSSecretStore struct {
+	smClient *secretsmanager.SecretsManager
+	kmsKeyID string
+}
+
+// NewAWSSecretStore creates a SecretStore backed by AWS Secrets Manager. Secrets are
+// encrypted with the KMS key kmsKeyID, or with the account's aws/secretsmanager key when empty.
+func NewAWSSecretStore(smClient *secretsmanager.SecretsManager, kmsKeyID string) *AWSSecretStore {
+	return &AWSSecretStore{smClient: smClient, kmsKeyID: kmsKeyID}
+}
+
+func (s *AWSSecretStore) PutSecret(ctx context.Context, name, value string, tags map[string]string) error {
+	input := &secretsmanager.CreateSecretInput{
+		Name:         aws.String(name),
+		SecretString: aws.String(value),
+	}
+	if s.kmsKeyID != "" {
+		input.KmsKeyId = aws.String(s.kmsKeyID)
+	}
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		input.Tags = append(input.Tags, &secretsmanager.Tag{Key: aws.String(k), Value: aws.String(tags[k])})
+	}
+	_, err := s.smClient.CreateSecretWithContext(ctx, input)
+	if err != nil {
+		return awsSecretError(fmt.Errorf("failed to create secret %s: %w", name, err))
+	}
+	return nil
+}
+
+func (s *AWSSecretStore) GetSecret(ctx context.Context, name string) (string, error) {
+	result, err := s.smClient.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(name),
+	})
+	if err != nil {
+		return "", awsSecretError(fmt.Errorf("failed to get secret value %s: %w", name, err))
+	}
+	return aws.StringValue(result.SecretString), nil
+}
+
+func (s *AWSSecretStore) DeleteSecret(ctx context.Context, name string) error {
+	_, err := s.smClient.DeleteSecretWithContext(ctx, &secretsmanager.DeleteSecretInput{
+		SecretId:                   aws.String(name),
+		ForceDeleteWithoutRecovery: aws.Bool(true),
+	})
+	if err != nil {
+		return awsSecretError(fmt.Errorf("failed to delete secret %s: %w", name, err))
+	}
+	return nil
+}
+
+// RotateSecret puts a new secret value. Secrets Manager moves the AWSCURRENT stage to it
+// and AWSPREVIOUS to the version it replaces.
+func (s *AWSSecretStore) RotateSecret(ctx context.Context, name, value string) error {
+	_, err := s.smClient.PutSecretValueWithContext(ctx, &secretsmanager.PutSecretValueInput{
+		SecretId:     aws.String(name),
+		SecretString: aws.String(value),
+	})
+	if err != nil {
+		return awsSecretError(fmt.Errorf("failed to put secret value %s: %w", name, err))
+	}
+	return nil
+}
+
+// RollbackSecret moves the AWSCURRENT stage to the version labelled AWSPREVIOUS. Secrets
+// Manager then labels the version it was removed from AWSPREVIOUS.
+func (s *AWSSecretStore) RollbackSecret(ctx context.Context, name string) error {
+	secret, err := s.smClient.DescribeSecretWithContext(ctx, &secretsmanager.DescribeSecretInput{
+		SecretId: aws.String(name),
+	})
+	if err != nil {
+		return awsSecretError(fmt.Errorf("failed to describe secret %s: %w", name, err))
+	}
+
+	var current, previous string
+	for versionID, stages := range secret.VersionIdsToStages {
+		for _, stage := range aws.StringValueSlice(stages) {
+			switch stage {
+			case awsCurrentStage:
+				current = versionID
+			case awsPreviousStage:
+				previous = versionID
+			}
+		}
+	}
+	if previous == "" {
+		return errs.NewNoPreviousSecretVersionError(name)
+	}
+
+	_, err = s.smClient.UpdateSecretVersionStageWithContext(ctx, &secretsmanager.UpdateSecretVersionStageInput{
+		SecretId:            aws.String(name),
+		VersionStage:        aws.String(awsCurrentStage),
+		MoveToVersionId:     aws.String(previous),
+		RemoveFromVersionId: aws.String(current),
+	})
+	if err != nil {
+		return awsSecretError(fmt.Errorf("failed to roll back secret %s: %w", name, err))
+	}
+	return nil
+}
+
+func (s *AWSSecretStore) ListSecrets(ctx context.Context) ([]SecretInfo, error) {
+	var secrets []SecretInfo
+	err := s.smClient.ListSecretsPagesWithContext(ctx, &secretsmanager.ListSecretsInput{},
+		func(page *secretsmanager.ListSecretsOutput, _ bool) bool {
+			for _, entry := range page.SecretList {
+				secrets = append(secrets, SecretInfo{
+					Name:      aws.StringValue(entry.Name),
+					CreatedAt: aws.TimeValue(entry.CreatedDate),
+				})
+			}
+			return true
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	return secrets, nil
+}
+
+// Staging labels Secrets Manager attaches to the current and the previous versions.
+const (
+	awsCurrentStage  = "AWSCURRENT"
+	awsPreviousStage = "AWSPREVIOUS"
+)
+
+// awsSecretError maps the Secrets Manager error codes callers act upon to errs errors.
+func awsSecretError(err error) error {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return err
+	}
+	switch aerr.Code() {
+	case secretsmanager.ErrCodeResourceNotFoundException:
+		return fmt.Errorf("%w: %w", errs.ErrSecretNotFound, err)
+	case secretsmanager.ErrCodeResourceExistsException:
+		return fmt.Errorf("%w: %w", errs.ErrSecretExistAlready, err)
+	}
+	return err
+}
@@ -0,0 +1,148 @@
+package storage_test
+
+import (
+	"context"
+	"crypto/rand"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"connector-recruitment/go-server/connectors/db"
+	"connector-recruitment/go-server/connectors/db/dbtest"
+	"connector-recruitment/go-server/connectors/storage"
+	"connector-recruitment/go-server/connectors/storage/storagetest"
+)
+
+func TestSqlStorage(t *testing.T) {
+	s, _ := newSqlStorage(t)
+	storagetest.Run(t, func(t *testing.T) storage.Storage { return s })
+}
+
+func TestSqlStorageDeleteConnectorWithoutSecret(t *testing.T) {
+	s, secrets := newSqlStorage(t)
+	storagetest.DeleteConnectorWithoutSecret(t, s, secrets)
+}
+
+func TestSqlStorageReconcile(t *testing.T) {
+	s, secrets := newSqlStorage(t)
+	ctx := context.Background()
+	tenant, err := s.SaveTenant(ctx, &storage.Tenant{ID: "reconcile", Name: "reconcile", Status: storage.TenantStatusActive})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := s.SaveConnector(ctx, &storage.Connector{
+		WorkspaceID:      tenant.ID,
+		DefaultChannelID: "C0",
+		Type:             storage.ConnectorTypeSlack,
+		Token:            "xoxb-token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := s.GetConnectorByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := secrets.DeleteSecret(ctx, c.SecretName); err != nil {
+		t.Fatal(err)
+	}
+
+	// a connector younger than the minimum age may be mid-creation
+	report, err := s.Reconcile(ctx, true, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.OrphanConnectors) != 0 || len(report.Repaired) != 0 {
+		t.Fatalf("Reconcile of a young connector = %+v, want no orphan", report)
+	}
+
+	report, err = s.Reconcile(ctx, true, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(report.OrphanConnectors, []string{id}) || !slices.Equal(report.Repaired, []string{id}) {
+		t.Fatalf("Reconcile = %+v, want connector %s repaired", report, id)
+	}
+	repaired, err := s.GetConnectorByID(ctx, id)
+	if err != nil {
+		t.Fatalf("GetConnectorByID of a repaired orphan: %v", err)
+	}
+	if repaired.Status != storage.ConnectorStatusSuspended || repaired.StatusReason == "" || repaired.Version != c.Version+1 {
+		t.Fatalf("repaired orphan = %+v, want it suspended with a reason", repaired)
+	}
+
+	// suspended already: nothing changes
+	if _, err := s.Reconcile(ctx, true, 0); err != nil {
+		t.Fatal(err)
+	}
+	if again, err := s.GetConnectorByID(ctx, id); err != nil || again.Version != repaired.Version {
+		t.Fatalf("GetConnectorByID after a second repair = %+v, %v, want version %d", again, err, repaired.Version)
+	}
+}
+
+func TestSqlStorageReconcileBypassesTheCache(t *testing.T) {
+	env := dbtest.NewDatabase(t)
+	pool := dbtest.NewPool(t, env)
+	if err := db.MigratePool(pool, env.PostgresDatabase); err != nil {
+		t.Fatal(err)
+	}
+	// a secret store without transactions, like the AWS one, behind the token cache
+	secrets := storage.NewMemorySecretStore()
+	s := storage.NewSqlStorage(pool, nil, storage.NewCachedSecretStore(secrets, 10, time.Hour), newSecretNamer(t), slog.Default())
+	ctx := context.Background()
+	tenant, err := s.SaveTenant(ctx, &storage.Tenant{ID: "cached", Name: "cached", Status: storage.TenantStatusActive})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := s.SaveConnector(ctx, &storage.Connector{
+		WorkspaceID:      tenant.ID,
+		DefaultChannelID: "C0",
+		Type:             storage.ConnectorTypeSlack,
+		Token:            "xoxb-token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := s.GetConnectorByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// cache the token, then delete it out of band
+	if _, err := s.GetConnectorToken(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	if err := secrets.DeleteSecret(ctx, c.SecretName); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := s.Reconcile(ctx, true, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(report.Repaired, []string{id}) {
+		t.Fatalf("Reconcile = %+v, want connector %s repaired despite the cached token", report, id)
+	}
+	if repaired, err := s.GetConnectorByID(ctx, id); err != nil || repaired.Status != storage.ConnectorStatusSuspended {
+		t.Fatalf("GetConnectorByID of a repaired orphan = %+v, %v, want it suspended", repaired, err)
+	}
+}
+
+// newSqlStorage returns a SqlStorage on a disposable database, keeping its secrets there.
+func newSqlStorage(t *testing.T) (*storage.SqlStorage, storage.SecretStore) {
+	t.Helper()
+	env := dbtest.NewDatabase(t)
+	pool := dbtest.NewPool(t, env)
+	if err := db.MigratePool(pool, env.PostgresDatabase); err != nil {
+		t.Fatal(err)
+	}
+	kek := make([]byte, storage.KEKSize)
+	if _, err := rand.Read(kek); err != nil {
+		t.Fatal(err)
+	}
+	secrets, err := storage.NewPostgresSecretStore(pool, kek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return storage.NewSqlStorage(pool, nil, secrets, newSecretNamer(t), slog.Default()), secrets
+}
@@ -0,0 +1,736 @@
+// Package storagetest checks that a storage.Storage implementation has the semantics the
+// service relies on: the errors, orderings, versions and audit events of the SqlStorage.
+// Every implementation must pass Run, so that the handler and service tests can use the
+// MemoryStorage in place of a database.
+//
+// Each check works with a tenant of its own and only looks at the data it created, so a
+// storage may be shared between checks.
+package storagetest
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"connector-recruitment/go-server/connectors/audit"
+	"connector-recruitment/go-server/connectors/errs"
+	"connector-recruitment/go-server/connectors/storage"
+)
+
+// timeout bounds each check.
+const timeout = time.Minute
+
+var checks = []struct {
+	name string
+	run  func(ctx context.Context, s storage.Storage) error
+}{
+	{"tenants", checkTenants},
+	{"connectors", checkConnectors},
+	{"connector versions", checkConnectorVersions},
+	{"failed secret change", checkFailedSecretChange},
+	{"delete connector", checkDeleteConnector},
+	{"connector without token", checkConnectorWithoutToken},
+	{"concurrent rotations", checkConcurrentRotations},
+	{"routing rules", checkRoutingRules},
+	{"messages", checkMessages},
+	{"audit events", checkAuditEvents},
+}
+
+// Run runs every check as a subtest of t, against a storage made by newStore for it.
+func Run(t *testing.T, newStore func(t *testing.T) storage.Storage) {
+	for _, c := range checks {
+		t.Run(c.name, func(t *testing.T) {
+			s := newStore(t)
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			if err := c.run(ctx, s); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+// DeleteConnectorWithoutSecret checks that s deletes a connector whose secret was deleted
+// from secrets, the secret store of s, behind its back.
+func DeleteConnectorWithoutSecret(t *testing.T, s storage.Storage, secrets storage.SecretStore) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	tenant, err := newTenant(ctx, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := newConnector(ctx, s, tenant.ID, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := secrets.DeleteSecret(ctx, c.SecretName); err != nil {
+		t.Fatalf("DeleteSecret: %v", err)
+	}
+	if err := s.DeleteConnector(ctx, c.ID, c.Version); err != nil {
+		t.Fatalf("DeleteConnector of a connector without secret: %v", err)
+	}
+	if _, err := s.GetConnectorByID(ctx, c.ID); !errors.Is(err, errs.ErrConnectorNotFound) {
+		t.Fatalf("GetConnectorByID of a deleted connector: got %v, want %v", err, errs.ErrConnectorNotFound)
+	}
+}
+
+func checkTenants(ctx context.Context, s storage.Storage) error {
+	first, err := newTenant(ctx, s)
+	if err != nil {
+		return err
+	}
+	if first.CreatedAt.IsZero() || first.Settings == nil {
+		return fmt.Errorf("SaveTenant returned %+v, want created_at and non-nil settings", first)
+	}
+	second, err := newTenant(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.SaveTenant(ctx, first); !errors.Is(err, errs.ErrTenantExistAlready) {
+		return fmt.Errorf("SaveTenant of an existing tenant: got %v, want %v", err, errs.ErrTenantExistAlready)
+	}
+	if _, err := s.GetTenantByID(ctx, randomID("missing")); !errors.Is(err, errs.ErrTenantNotFound) {
+		return fmt.Errorf("GetTenantByID of a missing tenant: got %v, want %v", err, errs.ErrTenantNotFound)
+	}
+
+	first.Name = "renamed"
+	first.Status = storage.TenantStatusSuspended
+	first.Settings = map[string]string{"plan": "free"}
+	updated, err := s.UpdateTenant(ctx, first,
+		[]storage.TenantField{storage.TenantFieldName, storage.TenantFieldStatus, storage.TenantFieldSettings})
+	if err != nil {
+		return fmt.Errorf("UpdateTenant: %w", err)
+	}
+	got, err := s.GetTenantByID(ctx, first.ID)
+	if err != nil {
+		return fmt.Errorf("GetTenantByID: %w", err)
+	}
+	if got.Name != "renamed" || got.Status != storage.TenantStatusSuspended || got.Settings["plan"] != "free" ||
+		updated.Name != got.Name {
+		return fmt.Errorf("GetTenantByID after UpdateTenant returned %+v", got)
+	}
+	// only the listed fields change
+	partial := &storage.Tenant{ID: first.ID, Name: "ignored", Status: storage.TenantStatusActive}
+	if _, err := s.UpdateTenant(ctx, partial, []storage.TenantField{storage.TenantFieldStatus}); err != nil {
+		return fmt.Errorf("UpdateTenant of the status: %w", err)
+	}
+	got, err = s.GetTenantByID(ctx, first.ID)
+	if err != nil {
+		return fmt.Errorf("GetTenantByID: %w", err)
+	}
+	if got.Name != "renamed" || got.Status != storage.TenantStatusActive || got.Settings["plan"] != "free" {
+		return fmt.Errorf("GetTenantByID after UpdateTenant of the status returned %+v", got)
+	}
+	if _, err := s.UpdateTenant(ctx, &storage.Tenant{ID: randomID("missing"), Name: "x"},
+		[]storage.TenantField{storage.TenantFieldName}); !errors.Is(err, errs.ErrTenantNotFound) {
+		return fmt.Errorf("UpdateTenant of a missing tenant: got %v, want %v", err, errs.ErrTenantNotFound)
+	}
+
+	all, err := s.GetAllTenants(ctx)
+	if err != nil {
+		return fmt.Errorf("GetAllTenants: %w", err)
+	}
+	if i, j := indexOfTenant(all, first.ID), indexOfTenant(all, second.ID); i < 0 || j < 0 || i > j {
+		return fmt.Errorf("GetAllTenants: want both tenants, oldest first, got them at %d and %d", i, j)
+	}
+	return nil
+}
+
+func checkConnectors(ctx context.Context, s storage.Storage) error {
+	tenant, err := newTenant(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.SaveConnector(ctx, &storage.Connector{WorkspaceID: randomID("missing"), Token: "t"}); !errors.Is(err, errs.ErrTenantNotFound) {
+		return fmt.Errorf("SaveConnector for a missing tenant: got %v, want %v", err, errs.ErrTenantNotFound)
+	}
+
+	first, err := newConnector(ctx, s, tenant.ID, "token-1")
+	if err != nil {
+		return err
+	}
+	if first.Version != 1 || first.Status != storage.ConnectorStatusActive || first.SecretName == "" || first.Token != "" {
+		return fmt.Errorf("GetConnectorByID of a new connector returned %+v (version %d)", first, first.Version)
+	}
+	if first.Settings.Webhook == nil || first.Settings.Webhook.Headers["X-Key"] != "value" {
+		return fmt.Errorf("GetConnectorByID lost the settings: %+v", first.Settings)
+	}
+	token, err := s.GetConnectorToken(ctx, first)
+	if err != nil || token != "token-1" {
+		return fmt.Errorf("GetConnectorToken: got %q, %v, want %q", token, err, "token-1")
+	}
+	second, err := newConnector(ctx, s, tenant.ID, "token-2")
+	if err != nil {
+		return err
+	}
+
+	// the service leaves the creation time to the storage
+	saving := time.Now().Add(-time.Second)
+	id, err := s.SaveConnector(ctx, &storage.Connector{WorkspaceID: tenant.ID, Type: storage.ConnectorTypeSlack, Token: "token-3"})
+	if err != nil {
+		return fmt.Errorf("SaveConnector without creation time: %w", err)
+	}
+	stamped, err := s.GetConnectorByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("GetConnectorByID: %w", err)
+	}
+	if stamped.CreatedAt.Before(saving) || !stamped.UpdatedAt.Equal(stamped.CreatedAt) {
+		return fmt.Errorf("SaveConnector without creation time stored created at %v, updated at %v", stamped.CreatedAt, stamped.UpdatedAt)
+	}
+
+	// changing a returned connector must not change the stored one
+	first.Settings.Webhook.Headers["X-Key"] = "changed"
+	again, err := s.GetConnectorByID(ctx, first.ID)
+	if err != nil {
+		return fmt.Errorf("GetConnectorByID: %w", err)
+	}
+	if again.Settings.Webhook.Headers["X-Key"] != "value" {
+		return errors.New("GetConnectorByID returned a connector sharing its settings with the stored one")
+	}
+
+	if _, err := s.GetConnectorByID(ctx, randomUUID()); !errors.Is(err, errs.ErrConnectorNotFound) {
+		return fmt.Errorf("GetConnectorByID of a missing connector: got %v, want %v", err, errs.ErrConnectorNotFound)
+	}
+
+	byTenant, err := s.GetConnectorsByWorkspaceID(ctx, tenant.ID)
+	if err != nil {
+		return fmt.Errorf("GetConnectorsByWorkspaceID: %w", err)
+	}
+	if len(byTenant) != 3 || !sortedByCreation(byTenant) {
+		return fmt.Errorf("GetConnectorsByWorkspaceID: want the 3 connectors ordered by creation, got %d", len(byTenant))
+	}
+	all, err := s.GetAllConnectors(ctx)
+	if err != nil {
+		return fmt.Errorf("GetAllConnectors: %w", err)
+	}
+	if indexOfConnector(all, first.ID) < 0 || indexOfConnector(all, second.ID) < 0 {
+		return errors.New("GetAllConnectors misses a connector")
+	}
+	return nil
+}
+
+func checkConnectorVersions(ctx context.Context, s storage.Storage) error {
+	tenant, err := newTenant(ctx, s)
+	if err != nil {
+		return err
+	}
+	c, err := newConnector(ctx, s, tenant.ID, "old")
+	if err != nil {
+		return err
+	}
+
+	err = s.RotateConnectorToken(ctx, c.ID, "new", c.Version+1)
+	var mismatch *errs.VersionMismatchError
+	if !errors.Is(err, errs.ErrVersionMismatch) || !errors.As(err, &mismatch) || mismatch.Current != c.Version {
+		return fmt.Errorf("RotateConnectorToken at a wrong version: got %v, want a mismatch at version %d", err, c.Version)
+	}
+	if err := s.RotateConnectorToken(ctx, c.ID, "new", c.Version); err != nil {
+		return fmt.Errorf("RotateConnectorToken: %w", err)
+	}
+	if err := expectConnector(ctx, s, c.ID, 2, "new"); err != nil {
+		return fmt.Errorf("after RotateConnectorToken: %w", err)
+	}
+	if err := s.RollbackConnectorToken(ctx, c.ID, 0); err != nil {
+		return fmt.Errorf("RollbackConnectorToken: %w", err)
+	}
+	if err := expectConnector(ctx, s, c.ID, 3, "old"); err != nil {
+		return fmt.Errorf("after RollbackConnectorToken: %w", err)
+	}
+	if err := s.RotateConnectorToken(ctx, randomUUID(), "t", 0); !errors.Is(err, errs.ErrConnectorNotFound) {
+		return fmt.Errorf("RotateConnectorToken of a missing connector: got %v, want %v", err, errs.ErrConnectorNotFound)
+	}
+
+	if err := s.SetConnectorStatus(ctx, c.ID, storage.ConnectorStatusSuspended, "token_revoked"); err != nil {
+		return fmt.Errorf("SetConnectorStatus: %w", err)
+	}
+	if err := expectConnector(ctx, s, c.ID, 4, "old"); err != nil {
+		return fmt.Errorf("after SetConnectorStatus: %w", err)
+	}
+	if err := s.SetConnectorStatus(ctx, randomUUID(), storage.ConnectorStatusActive, ""); !errors.Is(err, errs.ErrConnectorNotFound) {
+		return fmt.Errorf("SetConnectorStatus of a missing connector: got %v, want %v", err, errs.ErrConnectorNotFound)
+	}
+
+	checked := time.Now().UTC().Truncate(time.Second)
+	if err := s.SaveConnectorHealth(ctx, c.ID, storage.ConnectorHealth{LastCheckedAt: &checked, LastError: "missing_scope", Degraded: true}); err != nil {
+		return fmt.Errorf("SaveConnectorHealth: %w", err)
+	}
+	if err := expectConnector(ctx, s, c.ID, 4, "old"); err != nil {
+		return fmt.Errorf("after SaveConnectorHealth, which must not bump the version: %w", err)
+	}
+	got, err := s.GetConnectorByID(ctx, c.ID)
+	if err != nil {
+		return fmt.Errorf("GetConnectorByID: %w", err)
+	}
+	if got.Status != storage.ConnectorStatusSuspended || got.StatusReason != "token_revoked" ||
+		got.Health.LastCheckedAt == nil || !got.Health.LastCheckedAt.Equal(checked) ||
+		got.Health.LastHealthyAt != nil || got.Health.LastError != "missing_scope" || !got.Health.Degraded {
+		return fmt.Errorf("GetConnectorByID lost the status or health: %+v %+v", got, got.Health)
+	}
+	if err := s.SaveConnectorHealth(ctx, randomUUID(), storage.ConnectorHealth{}); !errors.Is(err, errs.ErrConnectorNotFound) {
+		return fmt.Errorf("SaveConnectorHealth of a missing connector: got %v, want %v", err, errs.ErrConnectorNotFound)
+	}
+	return nil
+}
+
+// checkFailedSecretChange rolls back the token of a new connector, which has no previous
+// version: neither the version nor the audit log may change.
+func checkFailedSecretChange(ctx context.Context, s storage.Storage) error {
+	tenant, err := newTenant(ctx, s)
+	if err != nil {
+		return err
+	}
+	c, err := newConnector(ctx, s, tenant.ID, "token")
+	if err != nil {
+		return err
+	}
+
+	if err := s.RollbackConnectorToken(ctx, c.ID, c.Version); !errors.Is(err, errs.ErrNoPreviousSecretVersion) {
+		return fmt.Errorf("RollbackConnectorToken without previous version: got %v, want %v", err, errs.ErrNoPreviousSecretVersion)
+	}
+	if err := expectConnector(ctx, s, c.ID, c.Version, "token"); err != nil {
+		return fmt.Errorf("after a failed RollbackConnectorToken: %w", err)
+	}
+	events, err := s.ListAuditEvents(ctx, storage.AuditFilter{ConnectorID: c.ID, Limit: 10})
+	if err != nil {
+		return fmt.Errorf("ListAuditEvents: %w", err)
+	}
+	if len(events) != 1 || events[0].Action != storage.AuditActionCreate {
+		return fmt.Errorf("ListAuditEvents after a failed RollbackConnectorToken: got %d events, want the create event only", len(events))
+	}
+	return nil
+}
+
+func checkDeleteConnector(ctx context.Context, s storage.Storage) error {
+	tenant, err := newTenant(ctx, s)
+	if err != nil {
+		return err
+	}
+	c, err := newConnector(ctx, s, tenant.ID, "token")
+	if err != nil {
+		return err
+	}
+
+	if err := s.DeleteConnector(ctx, c.ID, c.Version+1); !errors.Is(err, errs.ErrVersionMismatch) {
+		return fmt.Errorf("DeleteConnector at a wrong version: got %v, want %v", err, errs.ErrVersionMismatch)
+	}
+	if err := s.DeleteConnector(ctx, c.ID, c.Version); err != nil {
+		return fmt.Errorf("DeleteConnector: %w", err)
+	}
+	if _, err := s.GetConnectorByID(ctx, c.ID); !errors.Is(err, errs.ErrConnectorNotFound) {
+		return fmt.Errorf("GetConnectorByID of a deleted connector: got %v, want %v", err, errs.ErrConnectorNotFound)
+	}
+	if _, err := s.GetConnectorToken(ctx, c); !errors.Is(err, errs.ErrSecretNotFound) {
+		return fmt.Errorf("GetConnectorToken of a deleted connector: got %v, want %v", err, errs.ErrSecretNotFound)
+	}
+	if err := s.DeleteConnector(ctx, c.ID, 0); !errors.Is(err, errs.ErrConnectorNotFound) {
+		return fmt.Errorf("DeleteConnector of a deleted connector: got %v, want %v", err, errs.ErrConnectorNotFound)
+	}
+	return nil
+}
+
+// checkConnectorWithoutToken saves a connector with an empty token: it gets no secret, an
+// empty token, no token change, and can be deleted.
+func checkConnectorWithoutToken(ctx context.Context, s storage.Storage) error {
+	tenant, err := newTenant(ctx, s)
+	if err != nil {
+		return err
+	}
+	c, err := newConnector(ctx, s, tenant.ID, "")
+	if err != nil {
+		return err
+	}
+
+	if c.SecretName != "" {
+		return fmt.Errorf("SaveConnector without token: got secret name %q, want none", c.SecretName)
+	}
+	if err := expectConnector(ctx, s, c.ID, c.Version, ""); err != nil {
+		return err
+	}
+	if err := s.RotateConnectorToken(ctx, c.ID, "token", c.Version); !errors.Is(err, errs.ErrNoSecret) {
+		return fmt.Errorf("RotateConnectorToken without secret: got %v, want %v", err, errs.ErrNoSecret)
+	}
+	if err := s.DeleteConnector(ctx, c.ID, c.Version); err != nil {
+		return fmt.Errorf("DeleteConnector without secret: %w", err)
+	}
+	return nil
+}
+
+// checkConcurrentRotations rotates a token from several goroutines at the same version:
+// exactly one of them must win.
+func checkConcurrentRotations(ctx context.Context, s storage.Storage) error {
+	const writers = 8
+	tenant, err := newTenant(ctx, s)
+	if err != nil {
+		return err
+	}
+	c, err := newConnector(ctx, s, tenant.ID, "token")
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	results := make([]error, writers)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = s.RotateConnectorToken(ctx, c.ID, fmt.Sprintf("token-%d", i), c.Version)
+		}()
+	}
+	wg.Wait()
+
+	won := -1
+	for i, err := range results {
+		switch {
+		case err == nil && won >= 0:
+			return errors.New("two rotations at the same version succeeded")
+		case err == nil:
+			won = i
+		case !errors.Is(err, errs.ErrVersionMismatch):
+			return fmt.Errorf("RotateConnectorToken: %w", err)
+		}
+	}
+	if won < 0 {
+		return errors.New("no rotation succeeded")
+	}
+	return expectConnector(ctx, s, c.ID, c.Version+1, fmt.Sprintf("token-%d", won))
+}
+
+func checkRoutingRules(ctx context.Context, s storage.Storage) error {
+	tenant, err := newTenant(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	var saved []*storage.RoutingRule
+	for _, priority := range []int{1, 5, 1} {
+		rule, err := s.SaveRoutingRule(ctx, &storage.RoutingRule{
+			WorkspaceID: tenant.ID,
+			Name:        fmt.Sprintf("priority %d", priority),
+			Priority:    priority,
+		})
+		if err != nil {
+			return fmt.Errorf("SaveRoutingRule: %w", err)
+		}
+		if rule.ID == "" || rule.MatchLabels == nil || rule.Targets == nil {
+			return fmt.Errorf("SaveRoutingRule returned %+v, want an id and non-nil labels and targets", rule)
+		}
+		saved = append(saved, rule)
+	}
+
+	missingTenant := randomID("missing")
+	if _, err := s.SaveRoutingRule(ctx, &storage.RoutingRule{WorkspaceID: missingTenant, Name: "x"}); !errors.Is(err, errs.ErrTenantNotFound) {
+		return fmt.Errorf("SaveRoutingRule of a missing tenant: got %v, want %v", err, errs.ErrTenantNotFound)
+	}
+
+	rules, err := s.GetRoutingRulesByWorkspaceID(ctx, tenant.ID)
+	if err != nil {
+		return fmt.Errorf("GetRoutingRulesByWorkspaceID: %w", err)
+	}
+	if len(rules) != 3 || rules[0].ID != saved[1].ID || !sortedByCreation(rules[1:]) {
+		return errors.New("GetRoutingRulesByWorkspaceID: want the rules by priority, then by creation")
+	}
+
+	rule := saved[0]
+	rule.Name = "renamed"
+	rule.MatchLabels = map[string]string{"severity": "high"}
+	rule.Targets = []storage.RouteTarget{{ConnectorID: randomUUID(), ChannelID: "C1"}}
+	if _, err := s.UpdateRoutingRule(ctx, rule); err != nil {
+		return fmt.Errorf("UpdateRoutingRule: %w", err)
+	}
+	got, err := s.GetRoutingRuleByID(ctx, rule.ID)
+	if err != nil {
+		return fmt.Errorf("GetRoutingRuleByID: %w", err)
+	}
+	if got.Name != "renamed" || got.MatchLabels["severity"] != "high" || len(got.Targets) != 1 || got.Targets[0] != rule.Targets[0] {
+		return fmt.Errorf("GetRoutingRuleByID after UpdateRoutingRule returned %+v", got)
+	}
+
+	if err := s.DeleteRoutingRule(ctx, rule.ID); err != nil {
+		return fmt.Errorf("DeleteRoutingRule: %w", err)
+	}
+	missing := &storage.RoutingRule{ID: rule.ID, Name: "x"}
+	if _, err := s.GetRoutingRuleByID(ctx, rule.ID); !errors.Is(err, errs.ErrRoutingRuleNotFound) {
+		return fmt.Errorf("GetRoutingRuleByID of a deleted rule: got %v, want %v", err, errs.ErrRoutingRuleNotFound)
+	}
+	if _, err := s.UpdateRoutingRule(ctx, missing); !errors.Is(err, errs.ErrRoutingRuleNotFound) {
+		return fmt.Errorf("UpdateRoutingRule of a deleted rule: got %v, want %v", err, errs.ErrRoutingRuleNotFound)
+	}
+	if err := s.DeleteRoutingRule(ctx, rule.ID); !errors.Is(err, errs.ErrRoutingRuleNotFound) {
+		return fmt.Errorf("DeleteRoutingRule of a deleted rule: got %v, want %v", err, errs.ErrRoutingRuleNotFound)
+	}
+	return nil
+}
+
+func checkMessages(ctx context.Context, s storage.Storage) error {
+	tenant, err := newTenant(ctx, s)
+	if err != nil {
+		return err
+	}
+	connectorID := randomUUID()
+	statuses := []storage.DeliveryStatus{
+		storage.DeliveryStatusDelivered,
+		storage.DeliveryStatusFailed,
+		storage.DeliveryStatusDelivered,
+	}
+	for _, status := range statuses {
+		_, err := s.SaveMessage(ctx, &storage.MessageRecord{
+			ConnectorID:   connectorID,
+			WorkspaceID:   tenant.ID,
+			ConnectorType: storage.ConnectorTypeSlack,
+			Status:        status,
+			Latency:       1500 * time.Microsecond,
+			ContentHash:   fmt.Sprintf("%064d", 0),
+		})
+		if err != nil {
+			return fmt.Errorf("SaveMessage: %w", err)
+		}
+	}
+
+	all, err := s.ListMessages(ctx, storage.MessageFilter{ConnectorID: connectorID, Limit: 10})
+	if err != nil {
+		return fmt.Errorf("ListMessages: %w", err)
+	}
+	if len(all) != 3 || !sortedByCreationDesc(all) {
+		return fmt.Errorf("ListMessages: want the 3 messages, newest first, got %d", len(all))
+	}
+	if all[0].Latency != time.Millisecond {
+		return fmt.Errorf("ListMessages: got a latency of %s, want it stored in milliseconds", all[0].Latency)
+	}
+
+	first, err := s.ListMessages(ctx, storage.MessageFilter{WorkspaceID: tenant.ID, Limit: 2})
+	if err != nil {
+		return fmt.Errorf("ListMessages: %w", err)
+	}
+	last := first[len(first)-1]
+	rest, err := s.ListMessages(ctx, storage.MessageFilter{
+		WorkspaceID: tenant.ID,
+		After:       &storage.MessageCursor{CreatedAt: last.CreatedAt, ID: last.ID},
+		Limit:       2,
+	})
+	if err != nil {
+		return fmt.Errorf("ListMessages: %w", err)
+	}
+	if len(first) != 2 || len(rest) != 1 || rest[0].ID != all[2].ID {
+		return fmt.Errorf("ListMessages: pages of 2 gave %d then %d messages", len(first), len(rest))
+	}
+
+	failed, err := s.ListMessages(ctx, storage.MessageFilter{ConnectorID: connectorID, Status: storage.DeliveryStatusFailed, Limit: 10})
+	if err != nil {
+		return fmt.Errorf("ListMessages: %w", err)
+	}
+	if len(failed) != 1 {
+		return fmt.Errorf("ListMessages by status: got %d messages, want 1", len(failed))
+	}
+	future, err := s.ListMessages(ctx, storage.MessageFilter{ConnectorID: connectorID, From: time.Now().Add(time.Hour), Limit: 10})
+	if err != nil {
+		return fmt.Errorf("ListMessages: %w", err)
+	}
+	if len(future) != 0 {
+		return fmt.Errorf("ListMessages from a future time: got %d messages, want none", len(future))
+	}
+	return nil
+}
+
+func checkAuditEvents(ctx context.Context, s storage.Storage) error {
+	tenant, err := newTenant(ctx, s)
+	if err != nil {
+		return err
+	}
+	actor := randomID("actor")
+	ctx = audit.WithActor(ctx, audit.Actor{ID: actor, PeerAddress: "127.0.0.1:1234", Method: "/storagetest"})
+	c, err := newConnector(ctx, s, tenant.ID, "secret-token")
+	if err != nil {
+		return err
+	}
+	if err := s.RotateConnectorToken(ctx, c.ID, "other-secret-token", 0); err != nil {
+		return fmt.Errorf("RotateConnectorToken: %w", err)
+	}
+	if err := s.DeleteConnector(ctx, c.ID, 0); err != nil {
+		return fmt.Errorf("DeleteConnector: %w", err)
+	}
+
+	events, err := s.ListAuditEvents(ctx, storage.AuditFilter{ConnectorID: c.ID, Limit: 10})
+	if err != nil {
+		return fmt.Errorf("ListAuditEvents: %w", err)
+	}
+	if len(events) != 3 || !sortedByCreationDesc(events) {
+		return fmt.Errorf("ListAuditEvents: want 3 events, newest first, got %d", len(events))
+	}
+	actions := map[storage.AuditAction]*storage.AuditEvent{}
+	for _, e := range events {
+		if e.Actor != actor || e.PeerAddress != "127.0.0.1:1234" || e.Method != "/storagetest" || e.WorkspaceID != tenant.ID {
+			return fmt.Errorf("ListAuditEvents: event %+v does not name the actor and tenant", e)
+		}
+		for field, change := range e.Diff {
+			for _, value := range []string{string(change.Before), string(change.After)} {
+				if strings.Contains(value, "secret-token") {
+					return fmt.Errorf("ListAuditEvents: the %s change of %s holds the token", field, e.Action)
+				}
+			}
+		}
+		actions[e.Action] = e
+	}
+	create, rotate, del := actions[storage.AuditActionCreate], actions[storage.AuditActionRotateToken], actions[storage.AuditActionDelete]
+	if create == nil || rotate == nil || del == nil {
+		return fmt.Errorf("ListAuditEvents: want create, rotate_token and delete events, got %v", actions)
+	}
+	if len(create.Diff) == 0 || create.Diff["version"].Before != nil {
+		return errors.New("ListAuditEvents: the create event must list the fields of the connector, without before value")
+	}
+	if v := rotate.Diff["version"]; string(v.Before) != "1" || string(v.After) != "2" {
+		return fmt.Errorf("ListAuditEvents: the rotate_token event must change the version from 1 to 2, got %s to %s", v.Before, v.After)
+	}
+	if _, ok := rotate.Diff["status"]; ok {
+		return errors.New("ListAuditEvents: the rotate_token event lists an unchanged field")
+	}
+	if len(del.Diff) == 0 || del.Diff["version"].After != nil {
+		return errors.New("ListAuditEvents: the delete event must list the fields of the connector, without after value")
+	}
+
+	byActor, err := s.ListAuditEvents(ctx, storage.AuditFilter{Actor: actor, Limit: 2})
+	if err != nil {
+		return fmt.Errorf("ListAuditEvents: %w", err)
+	}
+	if len(byActor) != 2 || byActor[0].ID != events[0].ID {
+		return fmt.Errorf("ListAuditEvents by actor: got %d events, want the 2 newest", len(byActor))
+	}
+	return nil
+}
+
+// newTenant saves a tenant with a random ID.
+func newTenant(ctx context.Context, s storage.Storage) (*storage.Tenant, error) {
+	tenant, err := s.SaveTenant(ctx, &storage.Tenant{
+		ID:     randomID("storagetest"),
+		Name:   "storagetest",
+		Status: storage.TenantStatusActive,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("SaveTenant: %w", err)
+	}
+	return tenant, nil
+}
+
+// newConnector saves a webhook connector of the tenant and reads it back.
+func newConnector(ctx context.Context, s storage.Storage, tenantID, token string) (*storage.Connector, error) {
+	now := time.Now()
+	id, err := s.SaveConnector(ctx, &storage.Connector{
+		WorkspaceID:      tenantID,
+		DefaultChannelID: "C0",
+		Type:             storage.ConnectorTypeWebhook,
+		Settings: storage.Settings{Webhook: &storage.WebhookSettings{
+			URL:     "https://example.com/hook",
+			Headers: map[string]string{"X-Key": "value"},
+		}},
+		CreatedAt: now,
+		UpdatedAt: now,
+		Token:     token,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("SaveConnector: %w", err)
+	}
+	c, err := s.GetConnectorByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("GetConnectorByID of a new connector: %w", err)
+	}
+	return c, nil
+}
+
+// expectConnector checks the version and token of a connector.
+func expectConnector(ctx context.Context, s storage.Storage, id string, version int64, token string) error {
+	c, err := s.GetConnectorByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("GetConnectorByID: %w", err)
+	}
+	if c.Version != version {
+		return fmt.Errorf("got version %d, want %d", c.Version, version)
+	}
+	got, err := s.GetConnectorToken(ctx, c)
+	if err != nil {
+		return fmt.Errorf("GetConnectorToken: %w", err)
+	}
+	if got != token {
+		return fmt.Errorf("got token %q, want %q", got, token)
+	}
+	return nil
+}
+
+func sortedByCreation[T any](records []T) bool {
+	return sort.SliceIsSorted(records, func(i, j int) bool {
+		return before(creationOf(records[i]), creationOf(records[j]))
+	})
+}
+
+func sortedByCreationDesc[T any](records []T) bool {
+	return sort.SliceIsSorted(records, func(i, j int) bool {
+		return before(creationOf(records[j]), creationOf(records[i]))
+	})
+}
+
+type creation struct {
+	at time.Time
+	id string
+}
+
+func before(a, b creation) bool {
+	if !a.at.Equal(b.at) {
+		return a.at.Before(b.at)
+	}
+	return a.id < b.id
+}
+
+func creationOf(record any) creation {
+	switch r := record.(type) {
+	case *storage.Connector:
+		return creation{r.CreatedAt, r.ID}
+	case *storage.RoutingRule:
+		return creation{r.CreatedAt, r.ID}
+	case *storage.MessageRecord:
+		return creation{r.CreatedAt, r.ID}
+	case *storage.AuditEvent:
+		return creation{r.CreatedAt, r.ID}
+	default:
+		panic(fmt.Sprintf("storagetest: no creation time for %T", record))
+	}
+}
+
+func indexOfTenant(tenants []*storage.Tenant, id string) int {
+	for i, t := range tenants {
+		if t.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func indexOfConnector(connectors []*storage.Connector, id string) int {
+	for i, c := range connectors {
+		if c.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// randomID returns a unique ID starting with prefix.
+func randomID(prefix string) string {
+	return prefix + "-" + randomUUID()
+}
+
+func randomUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
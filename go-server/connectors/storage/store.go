@@ -2,21 +2,285 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
 	"time"
 )
 
+// ConnectorType identifies the integration a connector delivers messages to.
+type ConnectorType string
+
+const (
+	ConnectorTypeSlack   ConnectorType = "slack"
+	ConnectorTypeWebhook ConnectorType = "webhook"
+	ConnectorTypeEmail   ConnectorType = "email"
+)
+
+// WebhookSettings holds the non-secret configuration of a webhook connector.
+type WebhookSettings struct {
+	URL            string            `json:"url"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	TimeoutSeconds int               `json:"timeout_seconds,omitempty"`
+	MaxRetries     int               `json:"max_retries,omitempty"`
+}
+
+// EmailSettings holds the non-secret configuration of an email connector.
+type EmailSettings struct {
+	SMTPHost string   `json:"smtp_host"`
+	SMTPPort int      `json:"smtp_port"`
+	Username string   `json:"username,omitempty"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+// DigestSettings enables digest mode: messages are buffered for WindowSeconds and posted as a
+// single summary.
+type DigestSettings struct {
+	WindowSeconds int `json:"window_seconds"`
+}
+
+// Settings is the type specific configuration stored alongside a connector.
+type Settings struct {
+	Webhook *WebhookSettings `json:"webhook,omitempty"`
+	Email   *EmailSettings   `json:"email,omitempty"`
+	Digest  *DigestSettings  `json:"digest,omitempty"`
+}
+
+// ConnectorStatus tells whether a connector accepts messages.
+type ConnectorStatus string
+
+const (
+	ConnectorStatusActive ConnectorStatus = "active"
+	// ConnectorStatusSuspended connectors failed permanently and reject messages.
+	ConnectorStatusSuspended ConnectorStatus = "suspended"
+)
+
+// ConnectorHealth is the outcome of the health probes of a connector. LastCheckedAt is nil
+// until the connector is probed, LastHealthyAt until a probe succeeds. A degraded connector
+// passed the probe but could not be fully checked; LastError says what was missed.
+type ConnectorHealth struct {
+	LastCheckedAt *time.Time
+	LastHealthyAt *time.Time
+	LastError     string
+	Degraded      bool
+}
+
 type Connector struct {
 	ID               string
 	WorkspaceID      string
 	DefaultChannelID string
-	CreatedAt        time.Time
-	UpdatedAt        time.Time
-	Token            string
+	Type             ConnectorType
+	Settings         Settings
+	Status           ConnectorStatus
+	StatusReason     string // why the connector was suspended
+	Health           ConnectorHealth
+	SecretName       string    // name of the connector's secret in the secret store, empty without token
+	Version          int64     // incremented by every change but health probes, see SaveConnectorHealth
+	CreatedAt        time.Time // set by SaveConnector when zero
+	UpdatedAt        time.Time // set by SaveConnector when zero
+	// Token is the connector's secret: the slack bot token, the webhook signing secret
+	// or the SMTP password. It is only read by SaveConnector: connectors are loaded
+	// without it, see Storage.GetConnectorToken. An empty token stores no secret, as for
+	// an SMTP server without authentication.
+	Token string
+}
+
+// LogValue logs a connector without its token.
+func (c Connector) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", c.ID),
+		slog.String("workspace-id", c.WorkspaceID),
+		slog.String("type", string(c.Type)),
+		slog.String("status", string(c.Status)),
+		slog.String("secret-name", c.SecretName),
+	)
+}
+
+// String keeps the token out of %v and %s.
+func (c Connector) String() string {
+	return fmt.Sprintf("Connector{ID:%s WorkspaceID:%s Type:%s Status:%s}", c.ID, c.WorkspaceID, c.Type, c.Status)
+}
+
+// creationTimes returns the creation and update times SaveConnector stores for c: the
+// current time unless the caller set them.
+func (c Connector) creationTimes() (createdAt, updatedAt time.Time) {
+	createdAt, updatedAt = c.CreatedAt, c.UpdatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	if updatedAt.IsZero() {
+		updatedAt = createdAt
+	}
+	return createdAt, updatedAt
+}
+
+// TenantStatus tells whether connectors can be created for a tenant.
+type TenantStatus string
+
+const (
+	TenantStatusActive    TenantStatus = "active"
+	TenantStatusSuspended TenantStatus = "suspended"
+)
+
+// Tenant owns connectors and routing rules; its ID is the WorkspaceID of its connectors.
+type Tenant struct {
+	ID        string
+	Name      string
+	Status    TenantStatus
+	Settings  map[string]string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TenantField is a field of a tenant UpdateTenant can change.
+type TenantField string
+
+const (
+	TenantFieldName     TenantField = "name"
+	TenantFieldStatus   TenantField = "status"
+	TenantFieldSettings TenantField = "settings"
+)
+
+// RouteTarget is a connector a routed message is sent through. An empty ChannelID
+// means the connector's default channel.
+type RouteTarget struct {
+	ConnectorID string `json:"connector_id"`
+	ChannelID   string `json:"channel_id,omitempty"`
+}
+
+// RoutingRule sends messages whose labels contain every MatchLabels pair to its Targets.
+type RoutingRule struct {
+	ID          string
+	WorkspaceID string
+	Name        string
+	Priority    int
+	MatchLabels map[string]string
+	Targets     []RouteTarget
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Matches reports whether every label of the rule's selector is present in labels.
+func (r *RoutingRule) Matches(labels map[string]string) bool {
+	for k, v := range r.MatchLabels {
+		if got, ok := labels[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+// DeliveryStatus is the outcome of a send attempt.
+type DeliveryStatus string
+
+const (
+	DeliveryStatusDelivered DeliveryStatus = "delivered"
+	DeliveryStatusFailed    DeliveryStatus = "failed"
+)
+
+// MessageRecord is a persisted send attempt.
+type MessageRecord struct {
+	ID            string
+	ConnectorID   string
+	WorkspaceID   string
+	ConnectorType ConnectorType
+	Channel       string
+	TS            string
+	Status        DeliveryStatus
+	StatusCode    int
+	Error         string
+	Latency       time.Duration
+	ContentHash   string
+	CreatedAt     time.Time
+}
+
+// MessageFilter selects message records. Zero values are ignored. After is the keyset
+// cursor: only records strictly older than it in (CreatedAt, ID) order are returned.
+type MessageFilter struct {
+	ConnectorID string
+	WorkspaceID string
+	Status      DeliveryStatus
+	From        time.Time
+	To          time.Time
+	After       *MessageCursor
+	Limit       int
+}
+
+// MessageCursor is the position of the last record of a page.
+type MessageCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// AuditAction is the kind of change recorded by an audit event.
+type AuditAction string
+
+const (
+	AuditActionCreate        AuditAction = "create"
+	AuditActionDelete        AuditAction = "delete"
+	AuditActionRotateToken   AuditAction = "rotate_token"
+	AuditActionRollbackToken AuditAction = "rollback_token"
+	AuditActionSetStatus     AuditAction = "set_status"
+	AuditActionRenameSecret  AuditAction = "rename_secret"
+)
+
+// AuditChange is the JSON value of a connector field before and after a change. Before is
+// empty for a creation, After for a deletion.
+type AuditChange struct {
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// AuditEvent is a recorded change of a connector, keyed by field in Diff.
+type AuditEvent struct {
+	ID          string
+	Actor       string
+	PeerAddress string
+	Method      string
+	Action      AuditAction
+	ConnectorID string
+	WorkspaceID string
+	Diff        map[string]AuditChange
+	CreatedAt   time.Time
+}
+
+// AuditFilter selects audit events, like MessageFilter does message records.
+type AuditFilter struct {
+	WorkspaceID string
+	ConnectorID string
+	Actor       string
+	From        time.Time
+	To          time.Time
+	After       *MessageCursor
+	Limit       int
 }
 
 type Storage interface {
+	SaveTenant(context.Context, *Tenant) (*Tenant, error)
+	GetTenantByID(context.Context, string) (*Tenant, error)
+	GetAllTenants(context.Context) ([]*Tenant, error)
+	UpdateTenant(ctx context.Context, tenant *Tenant, fields []TenantField) (*Tenant, error)
+
 	SaveConnector(context.Context, *Connector) (string, error)
 	GetConnectorByID(context.Context, string) (*Connector, error)
+	GetConnectorToken(context.Context, *Connector) (string, error)
+	RotateConnectorToken(ctx context.Context, connectorID, token string, expectedVersion int64) error
+	RollbackConnectorToken(ctx context.Context, connectorID string, expectedVersion int64) error
 	GetAllConnectors(context.Context) ([]*Connector, error)
-	DeleteConnector(context.Context, string) error
+	GetConnectorsByWorkspaceID(context.Context, string) ([]*Connector, error)
+	DeleteConnector(ctx context.Context, connectorID string, expectedVersion int64) error
+	SetConnectorStatus(ctx context.Context, connectorID string, status ConnectorStatus, reason string) error
+	SaveConnectorHealth(ctx context.Context, connectorID string, health ConnectorHealth) error
+
+	SaveRoutingRule(context.Context, *RoutingRule) (*RoutingRule, error)
+	GetRoutingRuleByID(context.Context, string) (*RoutingRule, error)
+	GetRoutingRulesByWorkspaceID(context.Context, string) ([]*RoutingRule, error)
+	UpdateRoutingRule(context.Context, *RoutingRule) (*RoutingRule, error)
+	DeleteRoutingRule(context.Context, string) error
+
+	SaveMessage(context.Context, *MessageRecord) (string, error)
+	ListMessages(context.Context, MessageFilter) ([]*MessageRecord, error)
+
+	ListAuditEvents(context.Context, AuditFilter) ([]*AuditEvent, error)
 }
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"connector-recruitment/go-server/connectors/errs"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// pgForeignKeyViolation is the SQLSTATE of foreign key violations.
+const pgForeignKeyViolation = "23503"
+
+const tenantColumns = `id, name, status, settings, created_at, updated_at`
+
+// scanTenant scans a single tenants row into a Tenant struct.
+func scanTenant(row pgx.Row) (*Tenant, error) {
+	t := &Tenant{}
+	err := row.Scan(
+		&t.ID,
+		&t.Name,
+		&t.Status,
+		&t.Settings,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// SaveTenant inserts a new tenant and returns it as stored.
+func (s *SqlStorage) SaveTenant(ctx context.Context, tenant *Tenant) (*Tenant, error) {
+	query := `
+		INSERT INTO tenants (id, name, status, settings) 
+		VALUES ($1, $2, $3, $4) 
+		RETURNING ` + tenantColumns
+	saved, err := scanTenant(s.db.QueryRow(ctx, query,
+		tenant.ID,
+		tenant.Name,
+		tenant.Status,
+		nonNilLabels(tenant.Settings),
+	))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return nil, errs.NewTenantAlreadyExistError(tenant.ID)
+		}
+		return nil, fmt.Errorf("failed to save tenant: %w", err)
+	}
+	return saved, nil
+}
+
+// GetTenantByID retrieves a tenant by its ID.
+func (s *SqlStorage) GetTenantByID(ctx context.Context, tenantID string) (*Tenant, error) {
+	query := `SELECT ` + tenantColumns + ` FROM tenants WHERE id = $1`
+	tenant, err := scanTenant(s.db.QueryRow(ctx, query, tenantID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NewTenantNotFoundError(tenantID)
+		}
+		return nil, fmt.Errorf("failed to get tenant by ID: %w", err)
+	}
+	return tenant, nil
+}
+
+// GetAllTenants retrieves every tenant, oldest first.
+func (s *SqlStorage) GetAllTenants(ctx context.Context) ([]*Tenant, error) {
+	query := `SELECT ` + tenantColumns + ` FROM tenants ORDER BY created_at, id`
+	rows, err := s.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tenants: %w", err)
+	}
+	defer rows.Close()
+
+	var tenants []*Tenant
+	for rows.Next() {
+		t, err := scanTenant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tenant row: %w", err)
+		}
+		tenants = append(tenants, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return tenants, nil
+}
+
+// UpdateTenant sets the given fields of an existing tenant to those of tenant, leaving
+// the others as they are.
+func (s *SqlStorage) UpdateTenant(ctx context.Context, tenant *Tenant, fields []TenantField) (*Tenant, error) {
+	if len(fields) == 0 {
+		return s.GetTenantByID(ctx, tenant.ID)
+	}
+	set := make([]string, 0, len(fields))
+	args := []any{tenant.ID}
+	for _, field := range fields {
+		switch field {
+		case TenantFieldName:
+			args = append(args, tenant.Name)
+		case TenantFieldStatus:
+			args = append(args, tenant.Status)
+		case TenantFieldSettings:
+			args = append(args, nonNilLabels(tenant.Settings))
+		default:
+			return nil, fmt.Errorf("unknown tenant field %q", field)
+		}
+		set = append(set, fmt.Sprintf("%s = $%d", field, len(args)))
+	}
+	query := `
+		UPDATE tenants 
+		SET ` + strings.Join(set, ", ") + ` 
+		WHERE id = $1 
+		RETURNING ` + tenantColumns
+	updated, err := scanTenant(s.db.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NewTenantNotFoundError(tenant.ID)
+		}
+		return nil, fmt.Errorf("failed to update tenant: %w", err)
+	}
+	return updated, nil
+}
@@ -4,12 +4,33 @@ import (
 	"context"
 
 	pb "connector-recruitment/go-server/connectors/genproto"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 type ConnectorService interface {
-	GetConnector(context.Context, string) (*pb.Connector, error)
+	GetConnector(context.Context, string, bool) (*pb.Connector, error)
 	CreateConnector(context.Context, string, *pb.Connector) error
-	GetConnectors(context.Context) []*pb.Connector
-	DeleteConnector(context.Context, string) error
-	SendMessage(context.Context, string, string) error
+	GetConnectors(context.Context, bool) []*pb.Connector
+	DeleteConnector(context.Context, string, string) error
+	SendMessage(context.Context, *pb.SendMessageRequest) (*pb.Delivery, error)
+	BroadcastMessage(context.Context, string, []string, string) ([]*pb.BroadcastResult, error)
+	GetConnectorHealth(context.Context, string, bool) (*pb.ConnectorHealth, error)
+	RotateConnectorToken(context.Context, string, string, string, bool) (*pb.Connector, error)
+	RollbackConnectorToken(context.Context, string, string) (*pb.Connector, error)
+
+	CreateRoutingRule(context.Context, *pb.RoutingRule) (*pb.RoutingRule, error)
+	GetRoutingRule(context.Context, string) (*pb.RoutingRule, error)
+	ListRoutingRules(context.Context, string) ([]*pb.RoutingRule, error)
+	UpdateRoutingRule(context.Context, *pb.RoutingRule) (*pb.RoutingRule, error)
+	DeleteRoutingRule(context.Context, string) error
+	RouteMessage(context.Context, string, map[string]string, string) ([]*pb.BroadcastResult, error)
+
+	ListMessages(context.Context, *pb.ListMessagesRequest) ([]*pb.MessageRecord, string, error)
+	ListAuditEvents(context.Context, *pb.ListAuditEventsRequest) ([]*pb.AuditEvent, string, error)
+
+	CreateTenant(context.Context, *pb.Tenant) (*pb.Tenant, error)
+	GetTenant(context.Context, string) (*pb.Tenant, error)
+	ListTenants(context.Context) ([]*pb.Tenant, error)
+	UpdateTenant(context.Context, *pb.Tenant, *fieldmaskpb.FieldMask) (*pb.Tenant, error)
 }
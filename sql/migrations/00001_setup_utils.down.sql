@@ -0,0 +1,3 @@
+-- Drop the helper functions. The uuid-ossp extension is kept: postgres/init.sql creates it
+-- too, and other schemas of the database may use it.
+DROP FUNCTION IF EXISTS on_update_timestamp();
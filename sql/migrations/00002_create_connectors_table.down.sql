@@ -0,0 +1,2 @@
+-- Drop connectors table, along with its indexes
+DROP TABLE IF EXISTS connectors;
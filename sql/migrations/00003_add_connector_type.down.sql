@@ -0,0 +1,3 @@
+-- Remove connector type and type specific settings from connectors table
+ALTER TABLE connectors DROP COLUMN IF EXISTS settings;
+ALTER TABLE connectors DROP COLUMN IF EXISTS connector_type;
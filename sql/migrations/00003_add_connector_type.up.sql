@@ -0,0 +1,8 @@
+-- Add connector type and type specific settings to connectors table
+DO $$ 
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'connectors' AND column_name = 'connector_type') THEN
+        ALTER TABLE connectors ADD COLUMN connector_type varchar(32) NOT NULL DEFAULT 'slack';
+        ALTER TABLE connectors ADD COLUMN settings JSONB NOT NULL DEFAULT '{}'::jsonb;
+    END IF;
+END $$;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_connectors_workspace_id;
@@ -0,0 +1,2 @@
+-- index connectors by workspace so tenant wide lookups (e.g. broadcasts) avoid a full scan
+CREATE INDEX IF NOT EXISTS idx_connectors_workspace_id ON connectors(workspace_id);
@@ -0,0 +1,2 @@
+-- Drop routing_rules table, along with its indexes and trigger
+DROP TABLE IF EXISTS routing_rules;
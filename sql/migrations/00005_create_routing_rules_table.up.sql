@@ -0,0 +1,22 @@
+-- Create routing_rules table if it does not exist
+DO $$ 
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.tables WHERE table_name = 'routing_rules') THEN
+        CREATE TABLE routing_rules (
+            id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+            workspace_id varchar(255) NOT NULL,
+            name varchar(255) NOT NULL,
+            priority INTEGER NOT NULL DEFAULT 0,
+            match_labels JSONB NOT NULL DEFAULT '{}'::jsonb,
+            targets JSONB NOT NULL DEFAULT '[]'::jsonb,
+            created_at TIMESTAMPTZ DEFAULT NOW(),
+            updated_at TIMESTAMPTZ DEFAULT NOW()
+        );
+
+        CREATE INDEX idx_routing_rules_workspace_id ON routing_rules(workspace_id, priority DESC);
+
+        CREATE TRIGGER routing_rules_on_update
+            BEFORE UPDATE ON routing_rules
+            FOR EACH ROW EXECUTE PROCEDURE on_update_timestamp();
+    END IF;
+END $$;
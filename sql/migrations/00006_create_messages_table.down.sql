@@ -0,0 +1,2 @@
+-- Drop messages table: the message history is lost
+DROP TABLE IF EXISTS messages;
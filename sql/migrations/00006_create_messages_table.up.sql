@@ -0,0 +1,26 @@
+-- Create messages table if it does not exist. Rows outlive their connector, so there is
+-- deliberately no foreign key to connectors.
+DO $$ 
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.tables WHERE table_name = 'messages') THEN
+        CREATE TABLE messages (
+            id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+            connector_id UUID NOT NULL,
+            workspace_id varchar(255) NOT NULL,
+            connector_type varchar(32) NOT NULL,
+            channel varchar(255) NOT NULL DEFAULT '',
+            ts varchar(64) NOT NULL DEFAULT '',
+            status varchar(16) NOT NULL,
+            status_code INTEGER NOT NULL DEFAULT 0,
+            error TEXT NOT NULL DEFAULT '',
+            latency_ms BIGINT NOT NULL,
+            content_hash char(64) NOT NULL,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        );
+
+        -- keyset pagination walks (created_at, id) in descending order
+        CREATE INDEX idx_messages_created_at_id ON messages(created_at DESC, id DESC);
+        CREATE INDEX idx_messages_connector_id ON messages(connector_id, created_at DESC, id DESC);
+        CREATE INDEX idx_messages_workspace_id ON messages(workspace_id, created_at DESC, id DESC);
+    END IF;
+END $$;
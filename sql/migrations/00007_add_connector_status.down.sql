@@ -0,0 +1,3 @@
+-- Remove status from connectors table: suspended connectors become active again
+ALTER TABLE connectors DROP COLUMN IF EXISTS status_reason;
+ALTER TABLE connectors DROP COLUMN IF EXISTS status;
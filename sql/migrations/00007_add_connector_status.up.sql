@@ -0,0 +1,8 @@
+-- Add status to connectors table, so that connectors failing permanently can be suspended
+DO $$ 
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'connectors' AND column_name = 'status') THEN
+        ALTER TABLE connectors ADD COLUMN status varchar(32) NOT NULL DEFAULT 'active';
+        ALTER TABLE connectors ADD COLUMN status_reason text NOT NULL DEFAULT '';
+    END IF;
+END $$;
@@ -0,0 +1,4 @@
+-- Remove the outcome of the last health probe from connectors table
+ALTER TABLE connectors DROP COLUMN IF EXISTS last_health_error;
+ALTER TABLE connectors DROP COLUMN IF EXISTS last_healthy_at;
+ALTER TABLE connectors DROP COLUMN IF EXISTS last_checked_at;
@@ -0,0 +1,9 @@
+-- Add the outcome of the last health probe to connectors table
+DO $$ 
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'connectors' AND column_name = 'last_checked_at') THEN
+        ALTER TABLE connectors ADD COLUMN last_checked_at TIMESTAMPTZ;
+        ALTER TABLE connectors ADD COLUMN last_healthy_at TIMESTAMPTZ;
+        ALTER TABLE connectors ADD COLUMN last_health_error text NOT NULL DEFAULT '';
+    END IF;
+END $$;
@@ -0,0 +1,2 @@
+-- Drop connector_secrets table: the secrets of the postgres secret store are lost
+DROP TABLE IF EXISTS connector_secrets;
@@ -0,0 +1,15 @@
+-- Create connector_secrets table if it does not exist. It backs the postgres secret store:
+-- every value is encrypted with its own data key, itself encrypted with the key-encryption key.
+DO $$ 
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.tables WHERE table_name = 'connector_secrets') THEN
+        CREATE TABLE connector_secrets (
+            name varchar(512) NOT NULL,
+            version INTEGER NOT NULL,
+            ciphertext BYTEA NOT NULL,
+            encrypted_key BYTEA NOT NULL,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+            PRIMARY KEY (name, version)
+        );
+    END IF;
+END $$;
@@ -0,0 +1,4 @@
+-- Remove the name of the connector's secret from connectors table. Connectors are then read
+-- with their secret named after the connector id: run `server rename-secrets` with the
+-- template `{id}` before rolling back, or the renamed secrets are no longer found.
+ALTER TABLE connectors DROP COLUMN IF EXISTS secret_name;
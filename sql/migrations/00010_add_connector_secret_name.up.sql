@@ -0,0 +1,8 @@
+-- Add the name of the connector's secret to connectors table. Existing secrets are named after the connector id.
+DO $$ 
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'connectors' AND column_name = 'secret_name') THEN
+        ALTER TABLE connectors ADD COLUMN secret_name varchar(512) NOT NULL DEFAULT '';
+        UPDATE connectors SET secret_name = id::text;
+    END IF;
+END $$;
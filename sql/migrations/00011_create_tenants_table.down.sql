@@ -0,0 +1,3 @@
+-- Drop tenants table: connectors keep their workspace_id, without validation
+ALTER TABLE connectors DROP CONSTRAINT IF EXISTS connectors_workspace_id_fkey;
+DROP TABLE IF EXISTS tenants;
@@ -0,0 +1,27 @@
+-- Create tenants table if it does not exist, with a tenant for every workspace that
+-- already has connectors, and make connectors reference their tenant.
+DO $$ 
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.tables WHERE table_name = 'tenants') THEN
+        CREATE TABLE tenants (
+            id varchar(255) PRIMARY KEY,
+            name varchar(255) NOT NULL,
+            status varchar(32) NOT NULL DEFAULT 'active',
+            settings JSONB NOT NULL DEFAULT '{}'::jsonb,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+            updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        );
+
+        CREATE TRIGGER tenants_on_update
+            BEFORE UPDATE ON tenants
+            FOR EACH ROW EXECUTE PROCEDURE on_update_timestamp();
+
+        INSERT INTO tenants (id, name)
+        SELECT DISTINCT workspace_id, workspace_id FROM connectors;
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.table_constraints WHERE table_name = 'connectors' AND constraint_name = 'connectors_workspace_id_fkey') THEN
+        ALTER TABLE connectors ADD CONSTRAINT connectors_workspace_id_fkey
+            FOREIGN KEY (workspace_id) REFERENCES tenants(id);
+    END IF;
+END $$;
@@ -0,0 +1,2 @@
+-- Remove version from connectors table
+ALTER TABLE connectors DROP COLUMN IF EXISTS version;
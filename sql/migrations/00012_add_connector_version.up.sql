@@ -0,0 +1,7 @@
+-- Add version to connectors table, incremented by every change of a connector and exposed as its etag
+DO $$ 
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'connectors' AND column_name = 'version') THEN
+        ALTER TABLE connectors ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+    END IF;
+END $$;
@@ -0,0 +1,2 @@
+-- Drop audit_events table: the audit log is lost
+DROP TABLE IF EXISTS audit_events;
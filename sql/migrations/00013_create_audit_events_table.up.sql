@@ -0,0 +1,24 @@
+-- Create audit_events table if it does not exist. Events outlive their connector, so there
+-- is deliberately no foreign key to connectors.
+DO $$ 
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.tables WHERE table_name = 'audit_events') THEN
+        CREATE TABLE audit_events (
+            id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+            actor varchar(255) NOT NULL,
+            peer_address varchar(255) NOT NULL DEFAULT '',
+            method varchar(255) NOT NULL DEFAULT '',
+            action varchar(32) NOT NULL,
+            connector_id UUID NOT NULL,
+            workspace_id varchar(255) NOT NULL,
+            diff JSONB NOT NULL DEFAULT '{}'::jsonb,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        );
+
+        -- keyset pagination walks (created_at, id) in descending order
+        CREATE INDEX idx_audit_events_created_at_id ON audit_events(created_at DESC, id DESC);
+        CREATE INDEX idx_audit_events_connector_id ON audit_events(connector_id, created_at DESC, id DESC);
+        CREATE INDEX idx_audit_events_workspace_id ON audit_events(workspace_id, created_at DESC, id DESC);
+        CREATE INDEX idx_audit_events_actor ON audit_events(actor, created_at DESC, id DESC);
+    END IF;
+END $$;
@@ -0,0 +1,2 @@
+-- Drop the tenant reference of routing rules: they keep their workspace_id, without validation
+ALTER TABLE routing_rules DROP CONSTRAINT IF EXISTS routing_rules_workspace_id_fkey;
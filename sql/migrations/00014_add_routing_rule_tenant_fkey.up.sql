@@ -0,0 +1,13 @@
+-- Make routing rules reference their tenant, adding a tenant for every workspace that
+-- already has routing rules but no tenant.
+DO $$ 
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.table_constraints WHERE table_name = 'routing_rules' AND constraint_name = 'routing_rules_workspace_id_fkey') THEN
+        INSERT INTO tenants (id, name)
+        SELECT DISTINCT workspace_id, workspace_id FROM routing_rules
+        ON CONFLICT (id) DO NOTHING;
+
+        ALTER TABLE routing_rules ADD CONSTRAINT routing_rules_workspace_id_fkey
+            FOREIGN KEY (workspace_id) REFERENCES tenants(id);
+    END IF;
+END $$;
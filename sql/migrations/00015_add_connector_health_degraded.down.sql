@@ -0,0 +1,2 @@
+-- Remove health_degraded from connectors table
+ALTER TABLE connectors DROP COLUMN IF EXISTS health_degraded;
@@ -0,0 +1,7 @@
+-- Add to connectors table whether the last health probe found the connector degraded
+DO $$ 
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'connectors' AND column_name = 'health_degraded') THEN
+        ALTER TABLE connectors ADD COLUMN health_degraded BOOLEAN NOT NULL DEFAULT false;
+    END IF;
+END $$;
@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL migrations of the connectors database, so that the
+// server binary can migrate without the source tree.
+package migrations
+
+import "embed"
+
+// FS holds the migration files, named <version>_<name>.up.sql and <version>_<name>.down.sql.
+//
+//go:embed *.sql
+var FS embed.FS